@@ -36,6 +36,36 @@ func (c *Client) AsyncContext(ctx context.Context) <-chan error {
 	return errC
 }
 
+// endTaggedSentence registers r for tag and ends the sentence being written. The tag is registered
+// before the sentence is sent, so asyncLoop cannot miss a quick reply, and c.mu is not held while
+// writing, which would stall asyncLoop.
+//
+// On error, removed reports whether r was taken back from asyncLoop, or never given to it. Only
+// then must the caller clean up after r, otherwise asyncLoop has closed it or will do so.
+func (c *Client) endTaggedSentence(tag string, r sentenceProcessor) (removed bool, err error) {
+	c.mu.Lock()
+	registered := c.tags != nil
+	if registered {
+		c.tags[tag] = r
+	}
+	c.mu.Unlock()
+
+	if err := c.w.EndSentence(); err != nil {
+		c.mu.Lock()
+		_, removed = c.tags[tag]
+		delete(c.tags, tag)
+		c.mu.Unlock()
+
+		return removed || !registered, err
+	}
+
+	if !registered {
+		return true, ErrAsyncLoopEnded
+	}
+
+	return false, nil
+}
+
 func (c *Client) asyncLoopChan(ctx context.Context, errC chan<- error) {
 	defer close(errC)
 
@@ -0,0 +1,156 @@
+package routeros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// DefaultBatchWindow is the number of commands RunBatch keeps in flight.
+const DefaultBatchWindow = 64
+
+// ErrBatchStopped is the error of the commands that were not sent because an earlier one failed.
+var ErrBatchStopped = errors.New("batch stopped after an earlier error")
+
+// BatchOptions configure RunBatchOptions.
+type BatchOptions struct {
+	// Window is the maximum number of commands in flight, DefaultBatchWindow if zero.
+	Window int
+	// StopOnError stops sending commands once one fails. The commands in flight still complete.
+	StopOnError bool
+}
+
+// BatchResult is the outcome of one command of a batch.
+type BatchResult struct {
+	Reply *Reply
+	Err   error
+}
+
+// batchReply collects the reply of one command of a batch, and reports its index when done.
+type batchReply struct {
+	Reply
+	c     *Client
+	idx   int
+	err   error
+	doneC chan<- int
+}
+
+func (b *batchReply) close(err error) {
+	b.c.release(slotCommand)
+	b.err = err
+	b.doneC <- b.idx
+}
+
+// RunBatch simply calls RunBatchOptions() with the default options.
+func (c *Client) RunBatch(ctx context.Context, sentences [][]string) ([]BatchResult, error) {
+	return c.RunBatchOptions(ctx, sentences, BatchOptions{})
+}
+
+// RunBatchOptions sends sentences without waiting for each reply, keeping up to opts.Window of
+// them in flight, and returns the results in the order of sentences. The client is switched to
+// async mode if needed.
+//
+// A failing command does not stop the others unless opts.StopOnError is set, in which case the
+// first error is returned too. Errors writing to the device, or waiting for the limits set with
+// SetRateLimits, always stop the batch. If ctx is done first, ctx.Err() is returned and the
+// commands without reply get it as their error.
+func (c *Client) RunBatchOptions(ctx context.Context, sentences [][]string, opts BatchOptions) ([]BatchResult, error) {
+	c.logger().Debug("RunBatch", slog.Int("commands", len(sentences)), slog.Int("window", opts.Window))
+
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+
+	if !c.IsAsync() {
+		c.Async()
+	}
+
+	results := make([]BatchResult, len(sentences))
+	replies := make([]*batchReply, len(sentences))
+	// buffered for every command, so that asyncLoop never waits for this function
+	doneC := make(chan int, len(sentences))
+
+	var (
+		next, inFlight int
+		firstErr       error
+	)
+	for next < len(sentences) || inFlight > 0 {
+		if next < len(sentences) && firstErr == nil && inFlight < window {
+			b := &batchReply{c: c, idx: next, doneC: doneC}
+			replies[next] = b
+
+			pending, err := c.sendBatchCommand(ctx, sentences[next], b)
+			if pending {
+				// asyncLoop reports the command on doneC, even if the write failed
+				inFlight++
+			}
+			if err != nil {
+				if !pending {
+					results[next].Err = err
+				}
+				if firstErr == nil {
+					// the connection is broken, a limit failed or ctx is done: the replies in
+					// flight end with the async loop
+					firstErr = err
+				}
+			}
+			next++
+			continue
+		}
+
+		// stop sending: skip the commands left
+		if next < len(sentences) && firstErr != nil && inFlight == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			for i := range results {
+				if results[i].Reply == nil && results[i].Err == nil {
+					results[i].Err = ctx.Err()
+				}
+			}
+			return results, ctx.Err()
+		case i := <-doneC:
+			inFlight--
+			b := replies[i]
+			if b.err != nil {
+				results[i].Err = b.err
+				if opts.StopOnError && firstErr == nil {
+					firstErr = fmt.Errorf("batch command %d: %w", i, b.err)
+				}
+				continue
+			}
+			results[i].Reply = &b.Reply
+		}
+	}
+
+	for i := next; i < len(sentences); i++ {
+		results[i].Err = ErrBatchStopped
+	}
+	return results, firstErr
+}
+
+// sendBatchCommand sends one command of a batch. pending reports whether b was left to asyncLoop,
+// which then closes it, even if an error is returned.
+func (c *Client) sendBatchCommand(ctx context.Context, sentence []string, b *batchReply) (pending bool, err error) {
+	if err := c.acquire(ctx, slotCommand); err != nil {
+		return false, err
+	}
+
+	c.w.BeginSentence()
+	for _, word := range sentence {
+		c.w.WriteWord(word)
+	}
+
+	tag := fmt.Sprintf("b%d", c.incrementTag())
+	c.w.WriteWord(".tag=" + tag)
+
+	removed, err := c.endTaggedSentence(tag, b)
+	if removed {
+		c.release(slotCommand)
+	}
+	return !removed, err
+}
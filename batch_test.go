@@ -0,0 +1,93 @@
+package routeros
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/ip/address/add @b1 [{`address` `10.0.0.1/32`} {`interface` `lo`}]")
+		s.readSentence(t, "/ip/address/add @b2 [{`address` `10.0.0.2/32`} {`interface` `lo`}]")
+		s.writeSentence(t, "!done", ".tag=b2", "=ret=*2")
+		s.readSentence(t, "/ip/address/add @b3 [{`address` `bogus`} {`interface` `lo`}]")
+		s.writeSentence(t, "!done", ".tag=b1", "=ret=*1")
+		s.readSentence(t, "/ip/address/print @b4 []")
+		s.writeSentence(t, "!trap", ".tag=b3", "=message=invalid value for argument address")
+		s.writeSentence(t, "!done", ".tag=b3")
+		s.writeSentence(t, "!re", ".tag=b4", "=address=10.0.0.1/32")
+		s.writeSentence(t, "!done", ".tag=b4")
+	}()
+
+	results, err := c.RunBatchOptions(context.Background(), [][]string{
+		{"/ip/address/add", "=address=10.0.0.1/32", "=interface=lo"},
+		{"/ip/address/add", "=address=10.0.0.2/32", "=interface=lo"},
+		{"/ip/address/add", "=address=bogus", "=interface=lo"},
+		{"/ip/address/print"},
+	}, BatchOptions{Window: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "*1", results[0].Reply.Done.Map["ret"])
+	require.NoError(t, results[1].Err)
+	require.Equal(t, "*2", results[1].Reply.Done.Map["ret"])
+	require.EqualError(t, results[2].Err, "from RouterOS device: invalid value for argument address")
+	require.Nil(t, results[2].Reply)
+	require.NoError(t, results[3].Err)
+	require.Len(t, results[3].Reply.Re, 1)
+}
+
+func TestRunBatchStopOnError(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/ip/route/add @b1 [{`gateway` `bogus`}]")
+		// the reply ends at the !trap in async mode, so the client may be gone before a !done
+		s.writeSentence(t, "!trap", ".tag=b1", "=message=invalid value")
+	}()
+
+	results, err := c.RunBatchOptions(context.Background(), [][]string{
+		{"/ip/route/add", "=gateway=bogus"},
+		{"/ip/route/add", "=gateway=192.0.2.1"},
+	}, BatchOptions{Window: 1, StopOnError: true})
+	require.EqualError(t, err, "batch command 0: from RouterOS device: invalid value")
+	require.Error(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, ErrBatchStopped)
+}
+
+func TestRunBatchContext(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/system/identity/print @b1 []")
+		s.writeSentence(t, "!re", ".tag=b1", "=name=router")
+		s.writeSentence(t, "!done", ".tag=b1")
+		s.readSentence(t, "/system/resource/print @b2 []")
+		cancel()
+		// keep the connection open, so that the reply is not ended by EOF instead
+		<-finished
+	}()
+
+	results, err := c.RunBatchOptions(ctx, [][]string{
+		{"/system/identity/print"},
+		{"/system/resource/print"},
+	}, BatchOptions{Window: 1})
+	close(finished)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, context.Canceled)
+}
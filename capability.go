@@ -0,0 +1,131 @@
+package routeros
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Capability probes whether the device behind c has a feature.
+type Capability func(ctx context.Context, c *Client) (bool, error)
+
+var (
+	capabilitiesMu sync.RWMutex
+	capabilities   = map[string]Capability{
+		// RouterOS 7 rewrote routing, BGP and OSPF among others
+		"v7": versionCapability(7, 0, 0),
+		// reading files in chunks with /file/read
+		"file-read": versionCapability(7, 13, 0),
+		// the wifi package, formerly wifiwave2
+		"wifi": menuCapability("/interface/wifi"),
+	}
+)
+
+// RegisterCapability makes Supports(name) use probe. Registering a name again replaces its probe.
+func RegisterCapability(name string, probe Capability) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+
+	capabilities[name] = probe
+}
+
+func versionCapability(major, minor, patch int) Capability {
+	return func(ctx context.Context, c *Client) (bool, error) {
+		v, err := c.Version(ctx)
+		if err != nil {
+			return false, err
+		}
+		return v.AtLeast(major, minor, patch), nil
+	}
+}
+
+func menuCapability(path string) Capability {
+	return func(ctx context.Context, c *Client) (bool, error) {
+		return c.HasMenu(ctx, path)
+	}
+}
+
+// Supports reports whether the device has feature: either a name registered with
+// RegisterCapability, such as "v7" or "wifi", or a menu path such as "routing/bgp/connection".
+// Features are probed the first time they are asked for, and the answers are cached.
+func (c *Client) Supports(ctx context.Context, feature string) (bool, error) {
+	capabilitiesMu.RLock()
+	probe, ok := capabilities[feature]
+	capabilitiesMu.RUnlock()
+
+	if !ok {
+		return c.HasMenu(ctx, feature)
+	}
+	return c.cached(ctx, "capability "+feature, func(ctx context.Context) (bool, error) {
+		return probe(ctx, c)
+	})
+}
+
+// HasMenu reports whether the device has the menu at path, e.g. "/routing/bgp/connection" or
+// "interface/wifiwave2", or the command, e.g. "tool/ping". Commands are looked up with
+// /console/inspect, which RouterOS 6 does not have, so there they are reported as missing.
+// The answer is cached.
+func (c *Client) HasMenu(ctx context.Context, path string) (bool, error) {
+	path = "/" + strings.Trim(path, "/")
+
+	return c.cached(ctx, "menu "+path, func(ctx context.Context) (bool, error) {
+		// the query matches no item, so that only the existence of the menu is checked
+		_, err := c.RunContext(ctx, path+"/print", "=.proplist=.id", "?.id=*0")
+
+		var devErr *DeviceError
+		switch {
+		case err == nil:
+			return true, nil
+		case errors.As(err, &devErr) && strings.Contains(devErr.Sentence.Map["message"], "no such command"):
+			return c.hasCommand(ctx, path)
+		}
+		return false, err
+	})
+}
+
+// hasCommand reports whether path is a command, which has no print to probe.
+func (c *Client) hasCommand(ctx context.Context, path string) (bool, error) {
+	r, err := c.RunContext(ctx, "/console/inspect", "=request=child",
+		"=path="+strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", ","))
+
+	var devErr *DeviceError
+	switch {
+	case errors.As(err, &devErr):
+		// no /console/inspect, or no such path
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	for _, sen := range r.Re {
+		if sen.Map["type"] == "self" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// cached returns the answer cached for key, or probes and caches it. Errors are not cached.
+func (c *Client) cached(ctx context.Context, key string, probe func(context.Context) (bool, error)) (bool, error) {
+	c.capMu.Lock()
+	v, ok := c.caps[key]
+	c.capMu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := probe(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	c.capMu.Lock()
+	if c.caps == nil {
+		c.caps = make(map[string]bool)
+	}
+	c.caps[key] = v
+	c.capMu.Unlock()
+
+	return v, nil
+}
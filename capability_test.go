@@ -0,0 +1,93 @@
+package routeros
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasMenu(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/routing/bgp/connection/print @ [{`.proplist` `.id`}] ?[`.id=*0`]")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/routing/bgp/peer/print @ [{`.proplist` `.id`}] ?[`.id=*0`]")
+		s.writeSentence(t, "!trap", "=message=no such command prefix")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/console/inspect @ [{`request` `child`} {`path` `routing,bgp,peer`}]")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/tool/ping/print @ [{`.proplist` `.id`}] ?[`.id=*0`]")
+		s.writeSentence(t, "!trap", "=message=no such command")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/console/inspect @ [{`request` `child`} {`path` `tool,ping`}]")
+		s.writeSentence(t, "!re", "=type=self", "=name=ping", "=node-type=cmd")
+		s.writeSentence(t, "!re", "=type=child", "=name=address", "=node-type=arg")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/routing/ospf/instance/print @ [{`.proplist` `.id`}] ?[`.id=*0`]")
+		s.writeSentence(t, "!trap", "=message=not enough permissions (9)")
+		s.writeSentence(t, "!done")
+	}()
+
+	ctx := context.Background()
+	for _, path := range []string{"routing/bgp/connection", "/routing/bgp/connection/"} {
+		ok, err := c.HasMenu(ctx, path)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	ok, err := c.Supports(ctx, "routing/bgp/peer")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = c.HasMenu(ctx, "/routing/bgp/peer")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = c.HasMenu(ctx, "tool/ping")
+	require.NoError(t, err)
+	require.True(t, ok, "commands have no print")
+
+	_, err = c.HasMenu(ctx, "/routing/ospf/instance")
+	require.EqualError(t, err, "from RouterOS device: not enough permissions (9)")
+}
+
+func TestSupports(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/system/resource/print @ [{`.proplist` `version`}]")
+		s.writeSentence(t, "!re", "=version=6.49.10 (long-term)")
+		s.writeSentence(t, "!done")
+		s.readSentence(t, "/system/package/print @ [] ?[`name=ntp`]")
+		s.writeSentence(t, "!re", "=name=ntp")
+		s.writeSentence(t, "!done")
+	}()
+
+	RegisterCapability("test-ntp-package", func(ctx context.Context, c *Client) (bool, error) {
+		r, err := c.RunContext(ctx, "/system/package/print", "?name=ntp")
+		if err != nil {
+			return false, err
+		}
+		return len(r.Re) > 0, nil
+	})
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		feature string
+		want    bool
+	}{
+		{"v7", false},
+		{"file-read", false},
+		{"test-ntp-package", true},
+		{"test-ntp-package", true},
+	} {
+		ok, err := c.Supports(ctx, tc.feature)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, ok, tc.feature)
+	}
+}
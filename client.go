@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/tlsconfig"
 )
 
 // Client is a RouterOS API client.
@@ -37,6 +38,12 @@ type Client struct {
 
 	r proto.Reader
 	w proto.Writer
+
+	limiter limiter
+
+	capMu   sync.Mutex
+	version *Version
+	caps    map[string]bool
 }
 
 var (
@@ -112,7 +119,7 @@ func DialTLSTimeout(address, username, password string, tlsConfig *tls.Config, t
 func DialTLSContext(ctx context.Context, address, username, password string, tlsConfig *tls.Config) (*Client, error) {
 	conn, err := (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
 	if err != nil {
-		return nil, fmt.Errorf("could not connect to router os: %w", err)
+		return nil, fmt.Errorf("could not connect to router os: %w", tlsconfig.CheckAnonymousDH(err))
 	}
 	return newClientAndLogin(ctx, conn, username, password)
 }
@@ -136,6 +143,24 @@ func (c *Client) SetLogHandler(handler LogHandler) {
 	c.logMutex.Unlock()
 }
 
+// SetLimits replaces the protocol limits enforced on sentences read from the device.
+// By default proto.DefaultLimits are used.
+func (c *Client) SetLimits(l proto.Limits) {
+	c.r.SetLimits(l)
+}
+
+// SetDecoder sets the function converting attribute values read from the device into strings,
+// e.g. proto.Windows1251.Decoder("comment"). The original bytes stay available through Sentence.Bytes.
+func (c *Client) SetDecoder(d proto.Decoder) {
+	c.r.SetDecoder(d)
+}
+
+// SetEncoder sets the function converting attribute values into the bytes sent to the device,
+// e.g. proto.Windows1251.Encoder("comment").
+func (c *Client) SetEncoder(e proto.Encoder) {
+	c.w.SetEncoder(e)
+}
+
 func (c *Client) logger() *slog.Logger {
 	c.logMutex.Lock()
 	defer c.logMutex.Unlock()
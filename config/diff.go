@@ -0,0 +1,319 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/export"
+)
+
+// DefaultKeys are the attributes identifying the items of common menus. Items of menus that
+// are not listed are matched by name when they all have one, and by position otherwise.
+var DefaultKeys = map[string][]string{
+	"/interface":                  {"name"},
+	"/interface/bridge/port":      {"interface"},
+	"/interface/list/member":      {"list", "interface"},
+	"/interface/vlan":             {"name"},
+	"/ip/address":                 {"address", "interface"},
+	"/ip/dhcp-server/lease":       {"mac-address"},
+	"/ip/dhcp-server/network":     {"address"},
+	"/ip/dns/static":              {"name", "type"},
+	"/ip/firewall/address-list":   {"list", "address"},
+	"/ip/pool":                    {"name"},
+	"/ip/route":                   {"dst-address", "gateway", "routing-table"},
+	"/ip/service":                 {"name"},
+	"/ipv6/address":               {"address", "interface"},
+	"/ipv6/firewall/address-list": {"list", "address"},
+	"/system/script":              {"name"},
+	"/user":                       {"name"},
+}
+
+// DefaultIgnore are the attributes that change on their own (ids, counters, state) and are
+// left out of comparisons.
+var DefaultIgnore = []string{
+	".id", ".nextid", ".about",
+	"bytes", "packets",
+	"rx-byte", "tx-byte", "rx-packet", "tx-packet", "rx-drop", "tx-drop", "rx-error", "tx-error",
+	"fp-rx-byte", "fp-tx-byte", "fp-rx-packet", "fp-tx-packet", "tx-queue-drop",
+	"link-downs", "last-link-up-time", "last-link-down-time", "running", "actual-mtu",
+	"active", "status", "invalid", "uptime", "last-seen", "expires-after",
+	"run-count", "last-started",
+}
+
+// DiffOptions configure Diff.
+type DiffOptions struct {
+	// Keys overrides DefaultKeys for the menus it lists.
+	Keys map[string][]string
+	// Ignore lists attributes to leave out of the comparison, in addition to DefaultIgnore.
+	Ignore []string
+	// IncludeDynamic compares dynamic items too. They are skipped by default, as the device
+	// creates them on its own.
+	IncludeDynamic bool
+}
+
+// ChangeType tells how an item differs.
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// FieldChange is an attribute that differs between two items. A missing attribute is empty.
+type FieldChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Change is an item that differs between two snapshots.
+type Change struct {
+	Menu string     `json:"menu"`
+	Type ChangeType `json:"type"`
+	// Key identifies the item, e.g. "name=ether1", or "#3" for items matched by position.
+	Key string `json:"key"`
+	// Fields has the differing attributes of modified items.
+	Fields []FieldChange `json:"fields,omitempty"`
+	// Old and New are the compared attributes of the items, nil when missing.
+	Old Item `json:"old,omitempty"`
+	New Item `json:"new,omitempty"`
+}
+
+// Result is the list of differences between two snapshots.
+type Result struct {
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether the snapshots are the same.
+func (r *Result) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// Compare reads the menus at paths from a and b and returns their differences.
+func Compare(ctx context.Context, a, b *routeros.Client, paths []string, opts DiffOptions) (*Result, error) {
+	sa, err := Read(ctx, a, paths...)
+	if err != nil {
+		return nil, err
+	}
+	sb, err := Read(ctx, b, paths...)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(sa, sb, opts), nil
+}
+
+// Diff returns the differences from a to b. Menus are compared in the order of a, followed by
+// the menus only b has.
+func Diff(a, b *Snapshot, opts DiffOptions) *Result {
+	ignore := make(map[string]bool)
+	for _, k := range DefaultIgnore {
+		ignore[k] = true
+	}
+	for _, k := range opts.Ignore {
+		ignore[k] = true
+	}
+
+	var paths []string
+	for _, m := range a.Menus {
+		paths = append(paths, m.Path)
+	}
+	for _, m := range b.Menus {
+		if !slices.Contains(paths, m.Path) {
+			paths = append(paths, m.Path)
+		}
+	}
+
+	r := &Result{Changes: []Change{}}
+	for _, path := range paths {
+		var old, cur []Item
+		if m := a.Menu(path); m != nil {
+			old = m.Items
+		}
+		if m := b.Menu(path); m != nil {
+			cur = m.Items
+		}
+		r.Changes = append(r.Changes, diffMenu(path, old, cur, opts, ignore)...)
+	}
+	return r
+}
+
+func diffMenu(path string, a, b []Item, opts DiffOptions, ignore map[string]bool) []Change {
+	a, b = filterItems(a, opts, ignore), filterItems(b, opts, ignore)
+
+	keys := identityKeys(path, opts.Keys, a, b)
+	ka, kb := identities(a, keys), identities(b, keys)
+	byKey := make(map[string]Item, len(b))
+	for i, k := range kb {
+		byKey[k] = b[i]
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(a))
+	for i, k := range ka {
+		seen[k] = true
+		cur, ok := byKey[k]
+		if !ok {
+			changes = append(changes, Change{Menu: path, Type: Removed, Key: k, Old: a[i]})
+			continue
+		}
+		if fields := diffItems(a[i], cur); len(fields) > 0 {
+			changes = append(changes, Change{Menu: path, Type: Modified, Key: k, Fields: fields, Old: a[i], New: cur})
+		}
+	}
+	for i, k := range kb {
+		if !seen[k] {
+			changes = append(changes, Change{Menu: path, Type: Added, Key: k, New: b[i]})
+		}
+	}
+	return changes
+}
+
+// filterItems drops dynamic items and ignored attributes.
+func filterItems(items []Item, opts DiffOptions, ignore map[string]bool) []Item {
+	out := make([]Item, 0, len(items))
+	for _, item := range items {
+		if !opts.IncludeDynamic && isTrue(item["dynamic"]) {
+			continue
+		}
+
+		kept := make(Item, len(item))
+		for k, v := range item {
+			if !ignore[k] {
+				kept[k] = v
+			}
+		}
+		out = append(out, kept)
+	}
+	return out
+}
+
+// identityKeys returns the attributes identifying the items of the menu at path: those listed
+// in keys or DefaultKeys, or name if every item has one.
+func identityKeys(path string, keys map[string][]string, items ...[]Item) []string {
+	if k, ok := keys[path]; ok {
+		return k
+	}
+	if k, ok := DefaultKeys[path]; ok {
+		return k
+	}
+	for _, list := range items {
+		if !allHave(list, "name") {
+			return nil
+		}
+	}
+	return []string{"name"}
+}
+
+func allHave(items []Item, key string) bool {
+	for _, item := range items {
+		if item[key] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// identities returns the key of every item. Items sharing a key get a "#n" suffix after the
+// first one, and without keys items are identified by their position.
+func identities(items []Item, keys []string) []string {
+	ids := make([]string, len(items))
+	count := make(map[string]int)
+	for i, item := range items {
+		if len(keys) == 0 {
+			ids[i] = "#" + strconv.Itoa(i+1)
+			continue
+		}
+
+		parts := make([]string, len(keys))
+		for j, k := range keys {
+			parts[j] = k + "=" + export.Quote(item[k])
+		}
+		id := strings.Join(parts, " ")
+
+		count[id]++
+		if n := count[id]; n > 1 {
+			id += " #" + strconv.Itoa(n)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func diffItems(a, b Item) []FieldChange {
+	names := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		names = append(names, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	var fields []FieldChange
+	for _, k := range names {
+		if a[k] != b[k] {
+			fields = append(fields, FieldChange{Name: k, Old: a[k], New: b[k]})
+		}
+	}
+	return fields
+}
+
+// WriteText writes the differences in a form meant to be read by people:
+//
+//	/ip/address
+//	+ address=10.0.0.1/24 interface=ether2
+//	- address=192.168.88.1/24 interface=bridge
+//	~ name=ether1
+//	    comment: "" -> "uplink"
+func (r *Result) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	menu := ""
+	for _, c := range r.Changes {
+		if c.Menu != menu {
+			menu = c.Menu
+			sb.WriteString(menu + "\n")
+		}
+
+		switch c.Type {
+		case Added:
+			sb.WriteString("+ " + formatItem(c.New) + "\n")
+		case Removed:
+			sb.WriteString("- " + formatItem(c.Old) + "\n")
+		case Modified:
+			sb.WriteString("~ " + c.Key + "\n")
+			for _, f := range c.Fields {
+				fmt.Fprintf(&sb, "    %s: %q -> %q\n", f.Name, f.Old, f.New)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSON writes the differences to w as indented JSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// formatItem formats the attributes of item sorted by name, in export syntax.
+func formatItem(item Item) string {
+	names := sortedKeys(item)
+	parts := make([]string, len(names))
+	for i, k := range names {
+		parts[i] = k + "=" + export.Quote(item[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Snapshot{Menus: []Menu{
+		{Path: "/interface", Items: []Item{
+			{".id": "*1", "name": "ether1", "mtu": "1500", "rx-byte": "100", "running": "true"},
+			{".id": "*2", "name": "ether2", "mtu": "1500"},
+			{".id": "*3", "name": "pppoe-out1", "dynamic": "true"},
+		}},
+		{Path: "/ip/address", Items: []Item{
+			{".id": "*1", "address": "192.168.88.1/24", "interface": "bridge"},
+		}},
+		{Path: "/ip/firewall/filter", Items: []Item{
+			{"chain": "input", "action": "accept"},
+			{"chain": "input", "action": "drop"},
+		}},
+	}}
+	b := &Snapshot{Menus: []Menu{
+		{Path: "/interface", Items: []Item{
+			{".id": "*9", "name": "ether2", "mtu": "1500"},
+			{".id": "*8", "name": "ether1", "mtu": "9000", "rx-byte": "5", "comment": "uplink"},
+			{".id": "*7", "name": "ether3", "mtu": "1500"},
+		}},
+		{Path: "/ip/address", Items: []Item{
+			{".id": "*5", "address": "192.168.88.1/24", "interface": "bridge"},
+		}},
+		{Path: "/ip/firewall/filter", Items: []Item{
+			{"chain": "input", "action": "accept"},
+			{"chain": "input", "action": "reject"},
+		}},
+		{Path: "/system/identity", Items: []Item{{"name": "new"}}},
+	}}
+
+	r := Diff(a, b, DiffOptions{})
+	require.Equal(t, []Change{
+		{
+			Menu: "/interface", Type: Modified, Key: "name=ether1",
+			Fields: []FieldChange{{Name: "comment", New: "uplink"}, {Name: "mtu", Old: "1500", New: "9000"}},
+			Old:    Item{"name": "ether1", "mtu": "1500"},
+			New:    Item{"name": "ether1", "mtu": "9000", "comment": "uplink"},
+		},
+		{Menu: "/interface", Type: Added, Key: "name=ether3", New: Item{"name": "ether3", "mtu": "1500"}},
+		{
+			Menu: "/ip/firewall/filter", Type: Modified, Key: "#2",
+			Fields: []FieldChange{{Name: "action", Old: "drop", New: "reject"}},
+			Old:    Item{"chain": "input", "action": "drop"},
+			New:    Item{"chain": "input", "action": "reject"},
+		},
+		{Menu: "/system/identity", Type: Added, Key: "name=new", New: Item{"name": "new"}},
+	}, r.Changes)
+
+	var text bytes.Buffer
+	require.NoError(t, r.WriteText(&text))
+	require.Equal(t, `/interface
+~ name=ether1
+    comment: "" -> "uplink"
+    mtu: "1500" -> "9000"
++ mtu=1500 name=ether3
+/ip/firewall/filter
+~ #2
+    action: "drop" -> "reject"
+/system/identity
++ name=new
+`, text.String())
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+	var decoded Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, *r, decoded)
+}
+
+func TestDiffOptions(t *testing.T) {
+	a := &Snapshot{Menus: []Menu{{Path: "/ip/dns/static", Items: []Item{
+		{"name": "nas.lan", "address": "10.0.0.2", "ttl": "1d"},
+		{"name": "nas.lan", "address": "10.0.0.3", "ttl": "1d"},
+		{"name": "auto.lan", "address": "10.0.0.9", "dynamic": "true"},
+	}}}}
+	b := &Snapshot{Menus: []Menu{{Path: "/ip/dns/static", Items: []Item{
+		{"name": "nas.lan", "address": "10.0.0.3", "ttl": "1h"},
+	}}}}
+
+	r := Diff(a, b, DiffOptions{
+		Keys:           map[string][]string{"/ip/dns/static": {"name", "address"}},
+		Ignore:         []string{"ttl"},
+		IncludeDynamic: true,
+	})
+	require.Equal(t, []Change{
+		{Menu: "/ip/dns/static", Type: Removed, Key: "name=nas.lan address=10.0.0.2", Old: Item{"name": "nas.lan", "address": "10.0.0.2"}},
+		{Menu: "/ip/dns/static", Type: Removed, Key: "name=auto.lan address=10.0.0.9", Old: Item{"name": "auto.lan", "address": "10.0.0.9", "dynamic": "true"}},
+	}, r.Changes)
+
+	r = Diff(a, a, DiffOptions{})
+	require.True(t, r.Empty())
+}
+
+func TestIdentitiesDuplicates(t *testing.T) {
+	items := []Item{{"list": "a", "address": "1.1.1.1"}, {"list": "a", "address": "1.1.1.1"}, {"list": "my list", "address": "2.2.2.2"}}
+	require.Equal(t, []string{
+		"list=a address=1.1.1.1",
+		"list=a address=1.1.1.1 #2",
+		`list="my list" address=2.2.2.2`,
+	}, identities(items, []string{"list", "address"}))
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/schema"
+)
+
+// ErrConflict is returned by Restore with ConflictFail when an item differs on the device.
+var ErrConflict = errors.New("item differs on the device")
+
+// DefaultReadOnly are the attributes, besides DefaultIgnore, that Restore never writes. Where
+// the device has /console/inspect (RouterOS 7), the attributes its add and set commands do not
+// take are left out too, so this list matters for the others.
+var DefaultReadOnly = []string{
+	"dynamic", "default", "builtin", "default-name", "orig-mac-address", "slave", "inactive",
+	"invalid", "active", "running", "status", "network", "actual-interface", "gateway-status",
+	"immediate-gw",
+}
+
+// OrderedMenus are the menus whose items are processed in order, e.g. firewall rules. Restore
+// places the items it adds there before the next item of the snapshot found on the device.
+var OrderedMenus = []string{
+	"/ip/firewall/filter", "/ip/firewall/nat", "/ip/firewall/mangle", "/ip/firewall/raw",
+	"/ipv6/firewall/filter", "/ipv6/firewall/nat", "/ipv6/firewall/mangle", "/ipv6/firewall/raw",
+	"/ip/dns/static", "/queue/simple", "/routing/rule",
+}
+
+// Conflict selects what Restore does with items that exist on the device with other values.
+type Conflict int
+
+const (
+	// ConflictOverwrite sets the values of the snapshot. It is the default.
+	ConflictOverwrite Conflict = iota
+	// ConflictSkip leaves the items of the device as they are.
+	ConflictSkip
+	// ConflictFail makes Restore fail before changing anything.
+	ConflictFail
+)
+
+// RestoreOptions configure Restore.
+type RestoreOptions struct {
+	// Keys overrides DefaultKeys for the menus it lists. Items of menus without keys are added
+	// unless the device has an identical one.
+	Keys     map[string][]string
+	Conflict Conflict
+	// ReadOnly lists attributes never written, in addition to DefaultReadOnly.
+	ReadOnly []string
+	// DryRun only plans the commands, without running them.
+	DryRun bool
+}
+
+// RestoreReport tells what Restore did, or would do on a dry run.
+type RestoreReport struct {
+	// Commands are the planned commands, in the order they are run.
+	Commands [][]string
+	// Applied is the number of commands that were run successfully.
+	Applied int
+	// Conflicts are the items that differ on the device, from the device (Old) to the
+	// snapshot (New).
+	Conflicts []Change
+}
+
+// Restore writes the items of s to the device, menu by menu in the order of the snapshot.
+// Items are matched with those of the device by their identity keys, as in Diff: missing items
+// are added, and the ones that differ are handled as opts.Conflict says. Items the device has
+// and the snapshot does not are left alone. In OrderedMenus, added items keep their position
+// relative to the items of the snapshot the device already has.
+//
+// Every command is planned before the first one runs, so a dry run reports exactly what
+// would be sent. If a command fails, the report tells how many were applied.
+func Restore(ctx context.Context, c *routeros.Client, s *Snapshot, opts RestoreOptions) (*RestoreReport, error) {
+	readOnly := make(map[string]bool)
+	for _, list := range [][]string{DefaultIgnore, DefaultReadOnly, opts.ReadOnly} {
+		for _, k := range list {
+			readOnly[k] = true
+		}
+	}
+
+	sch := schema.New(c, schema.Options{})
+
+	r := &RestoreReport{}
+	for _, m := range s.Menus {
+		writable, err := writableAttrs(ctx, sch, m.Path)
+		if err != nil {
+			return r, err
+		}
+		cur, err := Read(ctx, c, m.Path)
+		if err != nil {
+			return r, err
+		}
+		device := cur.Menus[0].Items
+		r.plan(m, device, opts, menuReadOnly(readOnly, writable, m.Items, device))
+	}
+
+	if opts.Conflict == ConflictFail && len(r.Conflicts) > 0 {
+		return r, fmt.Errorf("%w: %s %s", ErrConflict, r.Conflicts[0].Menu, r.Conflicts[0].Key)
+	}
+	if opts.DryRun {
+		return r, nil
+	}
+
+	for _, cmd := range r.Commands {
+		if _, err := c.RunArgsContext(ctx, cmd); err != nil {
+			return r, fmt.Errorf("%s: %w", cmd[0], err)
+		}
+		r.Applied++
+	}
+	return r, nil
+}
+
+func (r *RestoreReport) plan(m Menu, device []Item, opts RestoreOptions, readOnly map[string]bool) {
+	device = filterItems(device, DiffOptions{}, nil)
+
+	// menus such as /system/identity have a single item without id, changed with a plain set
+	if len(device) == 1 && device[0][".id"] == "" {
+		for _, item := range m.Items {
+			r.update(m.Path, "", nil, device[0], item, opts, readOnly)
+		}
+		return
+	}
+
+	keys := identityKeys(m.Path, opts.Keys, m.Items, device)
+	ids, deviceIDs := identities(m.Items, keys), identities(device, keys)
+
+	byKey := make(map[string]Item, len(device))
+	for i, k := range deviceIDs {
+		byKey[k] = device[i]
+	}
+
+	// the device item of every item of the snapshot, nil if missing
+	found := make([]Item, len(m.Items))
+	candidates := device
+	for i, item := range m.Items {
+		if len(keys) > 0 {
+			found[i] = byKey[ids[i]]
+			continue
+		}
+
+		// a device item stands for one item of the snapshot only
+		if j := findItem(candidates, item, readOnly); j >= 0 {
+			found[i] = candidates[j]
+			candidates = append(candidates[:j:j], candidates[j+1:]...)
+		}
+	}
+
+	ordered := slices.Contains(OrderedMenus, m.Path)
+	for i, item := range m.Items {
+		cur := found[i]
+		if cur == nil {
+			cmd := addCommand(m.Path, item, readOnly)
+			if next := firstID(found[i+1:]); ordered && next != "" {
+				cmd = append(cmd, "=place-before="+next)
+			}
+			r.Commands = append(r.Commands, cmd)
+			continue
+		}
+		if len(keys) > 0 {
+			r.update(m.Path, ids[i], []string{"=.id=" + cur[".id"]}, cur, item, opts, readOnly)
+		}
+	}
+}
+
+// firstID returns the id of the first item that is not nil.
+func firstID(items []Item) string {
+	for _, item := range items {
+		if item != nil {
+			return item[".id"]
+		}
+	}
+	return ""
+}
+
+// writableAttrs returns the arguments of the add and set commands of the menu at path, or nil
+// if the device cannot tell.
+func writableAttrs(ctx context.Context, sch *schema.Schema, path string) (map[string]bool, error) {
+	var writable map[string]bool
+	for _, cmd := range []string{"/add", "/set"} {
+		n, err := sch.Lookup(ctx, path+cmd)
+		switch {
+		case errors.Is(err, schema.ErrNoInspect):
+			return nil, nil
+		case errors.Is(err, schema.ErrNotFound):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		if writable == nil {
+			writable = make(map[string]bool)
+		}
+		for _, arg := range n.Children {
+			if arg.Type == schema.Arg {
+				writable[arg.Name] = true
+			}
+		}
+	}
+	return writable, nil
+}
+
+// menuReadOnly adds to readOnly the attributes of items that are not writable, unless writable
+// is nil.
+func menuReadOnly(readOnly, writable map[string]bool, lists ...[]Item) map[string]bool {
+	if writable == nil {
+		return readOnly
+	}
+
+	out := make(map[string]bool, len(readOnly))
+	for k := range readOnly {
+		out[k] = true
+	}
+	for _, items := range lists {
+		for _, item := range items {
+			for k := range item {
+				if !writable[k] {
+					out[k] = true
+				}
+			}
+		}
+	}
+	return out
+}
+
+// update plans the set command turning the device item cur into item, if they differ.
+func (r *RestoreReport) update(path, key string, selector []string, cur, item Item, opts RestoreOptions, readOnly map[string]bool) {
+	var fields []FieldChange
+	for _, k := range sortedKeys(item) {
+		if !readOnly[k] && cur[k] != item[k] {
+			fields = append(fields, FieldChange{Name: k, Old: cur[k], New: item[k]})
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	r.Conflicts = append(r.Conflicts, Change{Menu: path, Type: Modified, Key: key, Fields: fields, Old: cur, New: item})
+	if opts.Conflict != ConflictOverwrite {
+		return
+	}
+
+	cmd := append([]string{path + "/set"}, selector...)
+	for _, f := range fields {
+		cmd = append(cmd, "="+f.Name+"="+f.New)
+	}
+	r.Commands = append(r.Commands, cmd)
+}
+
+func addCommand(path string, item Item, readOnly map[string]bool) []string {
+	cmd := []string{path + "/add"}
+	for _, k := range sortedKeys(item) {
+		// empty values are the defaults of a new item, and some attributes refuse them
+		if !readOnly[k] && item[k] != "" {
+			cmd = append(cmd, "="+k+"="+item[k])
+		}
+	}
+	return cmd
+}
+
+// findItem returns the index of the first of items with the same writable attributes as item,
+// or -1. An attribute missing from one of them must be empty in the other.
+func findItem(items []Item, item Item, readOnly map[string]bool) int {
+	for i, cur := range items {
+		if sameAttrs(cur, item, readOnly) && sameAttrs(item, cur, readOnly) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameAttrs reports whether b has the writable attributes of a.
+func sameAttrs(a, b Item, readOnly map[string]bool) bool {
+	for k, v := range a {
+		if !readOnly[k] && b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(item Item) []string {
+	keys := make([]string, 0, len(item))
+	for k := range item {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns the planned commands, one per line, in the form of the API.
+func (r *RestoreReport) String() string {
+	var sb strings.Builder
+	for _, cmd := range r.Commands {
+		sb.WriteString(strings.Join(cmd, " ") + "\n")
+	}
+	return sb.String()
+}
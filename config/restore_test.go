@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+var restoreSnapshot = &Snapshot{Menus: []Menu{
+	{Path: "/system/identity", Items: []Item{{"name": "new-router"}}},
+	{Path: "/ip/address", Items: []Item{
+		{"address": "192.168.88.1/24", "interface": "bridge", "comment": "lan"},
+		{"address": "10.0.0.2/30", "interface": "ether1", "comment": ""},
+	}},
+	{Path: "/ip/firewall/filter", Items: []Item{
+		{"chain": "input", "action": "accept", "connection-state": "established,related"},
+		{"chain": "input", "action": "drop", "in-interface": "ether1"},
+	}},
+}}
+
+// serveNoInspect answers the first /console/inspect of a restore as RouterOS 6 does.
+func serveNoInspect(t *testing.T, s *routerostest.Server, path string) {
+	s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `"+path+"`}]")
+	s.WriteSentence(t, "!trap", "=message=no such command prefix")
+	s.WriteSentence(t, "!done")
+}
+
+func serveDevice(t *testing.T, s *routerostest.Server) {
+	serveNoInspect(t, s, "system,identity,add")
+	s.ReadSentence(t, "/system/identity/print @ []")
+	s.WriteSentence(t, "!re", "=name=MikroTik")
+	s.WriteSentence(t, "!done")
+	s.ReadSentence(t, "/ip/address/print @ []")
+	s.WriteSentence(t, "!re", "=.id=*1", "=address=192.168.88.1/24", "=interface=bridge", "=comment=defconf", "=network=192.168.88.0")
+	s.WriteSentence(t, "!re", "=.id=*2", "=address=172.16.0.5/24", "=interface=ether1", "=dynamic=true")
+	s.WriteSentence(t, "!done")
+	s.ReadSentence(t, "/ip/firewall/filter/print @ []")
+	s.WriteSentence(t, "!re", "=.id=*A", "=chain=input", "=action=accept", "=connection-state=established,related", "=bytes=100")
+	s.WriteSentence(t, "!done")
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() { serveDevice(t, s) })
+
+	r, err := Restore(context.Background(), c, restoreSnapshot, RestoreOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"/system/identity/set", "=name=new-router"},
+		{"/ip/address/set", "=.id=*1", "=comment=lan"},
+		{"/ip/address/add", "=address=10.0.0.2/30", "=interface=ether1"},
+		{"/ip/firewall/filter/add", "=action=drop", "=chain=input", "=in-interface=ether1"},
+	}, r.Commands)
+	require.Equal(t, 0, r.Applied)
+	require.Len(t, r.Conflicts, 2)
+	require.Equal(t, "address=192.168.88.1/24 interface=bridge", r.Conflicts[1].Key)
+	require.Equal(t, []FieldChange{{Name: "comment", Old: "defconf", New: "lan"}}, r.Conflicts[1].Fields)
+
+	require.Equal(t, `/system/identity/set =name=new-router
+/ip/address/set =.id=*1 =comment=lan
+/ip/address/add =address=10.0.0.2/30 =interface=ether1
+/ip/firewall/filter/add =action=drop =chain=input =in-interface=ether1
+`, r.String())
+}
+
+func TestRestoreSkipConflicts(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		serveDevice(t, s)
+		s.ReadSentence(t, "/ip/address/add @ [{`address` `10.0.0.2/30`} {`interface` `ether1`}]")
+		s.WriteSentence(t, "!done", "=ret=*3")
+		s.ReadSentence(t, "/ip/firewall/filter/add @ [{`action` `drop`} {`chain` `input`} {`in-interface` `ether1`}]")
+		s.WriteSentence(t, "!trap", "=message=input does not match any value of interface")
+		s.WriteSentence(t, "!done")
+	})
+
+	r, err := Restore(context.Background(), c, restoreSnapshot, RestoreOptions{Conflict: ConflictSkip})
+	require.EqualError(t, err, "/ip/firewall/filter/add: from RouterOS device: input does not match any value of interface")
+	require.Len(t, r.Commands, 2)
+	require.Equal(t, 1, r.Applied)
+	require.Len(t, r.Conflicts, 2)
+}
+
+func TestRestoreFailOnConflict(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() { serveDevice(t, s) })
+
+	r, err := Restore(context.Background(), c, restoreSnapshot, RestoreOptions{Conflict: ConflictFail})
+	require.ErrorIs(t, err, ErrConflict)
+	require.Equal(t, 0, r.Applied)
+}
+
+func TestRestoreOrderedMenu(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		serveNoInspect(t, s, "ip,firewall,filter,add")
+		s.ReadSentence(t, "/ip/firewall/filter/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*A", "=chain=input", "=action=accept", "=protocol=icmp")
+		s.WriteSentence(t, "!re", "=.id=*B", "=chain=input", "=action=drop")
+		s.WriteSentence(t, "!done")
+	})
+
+	snap := &Snapshot{Menus: []Menu{{Path: "/ip/firewall/filter", Items: []Item{
+		{"chain": "input", "action": "accept", "connection-state": "established,related"},
+		{"chain": "input", "action": "accept", "protocol": "icmp"},
+		{"chain": "input", "action": "accept", "in-interface": "bridge"},
+		{"chain": "input", "action": "drop"},
+		{"chain": "forward", "action": "fasttrack-connection"},
+	}}}}
+
+	r, err := Restore(context.Background(), c, snap, RestoreOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"/ip/firewall/filter/add", "=action=accept", "=chain=input", "=connection-state=established,related", "=place-before=*A"},
+		{"/ip/firewall/filter/add", "=action=accept", "=chain=input", "=in-interface=bridge", "=place-before=*B"},
+		{"/ip/firewall/filter/add", "=action=fasttrack-connection", "=chain=forward"},
+	}, r.Commands)
+}
+
+func TestRestoreMatchesWholeItems(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		serveNoInspect(t, s, "ip,firewall,filter,add")
+		s.ReadSentence(t, "/ip/firewall/filter/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*A", "=chain=input", "=action=accept", "=protocol=icmp")
+		s.WriteSentence(t, "!re", "=.id=*B", "=chain=input", "=action=drop", "=in-interface=ether1")
+		s.WriteSentence(t, "!done")
+	})
+
+	snap := &Snapshot{Menus: []Menu{{Path: "/ip/firewall/filter", Items: []Item{
+		{"chain": "input", "action": "accept", "protocol": "icmp"},
+		// the same rule twice: the device has it once
+		{"chain": "input", "action": "accept", "protocol": "icmp"},
+		// wider than the device rule, which also matches in-interface
+		{"chain": "input", "action": "drop"},
+	}}}}
+
+	r, err := Restore(context.Background(), c, snap, RestoreOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"/ip/firewall/filter/add", "=action=accept", "=chain=input", "=protocol=icmp"},
+		{"/ip/firewall/filter/add", "=action=drop", "=chain=input"},
+	}, r.Commands)
+}
+
+func TestRestoreFullPrint(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address,add`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=add", "=node-type=cmd")
+		for _, arg := range []string{"address", "comment", "disabled", "interface"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+arg, "=node-type=arg")
+		}
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address,set`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=set", "=node-type=cmd")
+		for _, arg := range []string{"address", "comment", "disabled", "interface", "numbers"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+arg, "=node-type=arg")
+		}
+		s.WriteSentence(t, "!done")
+
+		s.ReadSentence(t, "/ip/address/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*1", "=address=192.168.88.1/24", "=network=192.168.88.0", "=interface=bridge",
+			"=actual-interface=bridge", "=vrf=main", "=invalid=false", "=dynamic=false", "=disabled=false", "=comment=defconf")
+		s.WriteSentence(t, "!done")
+	})
+
+	// as taken by Read from another device; vrf is only known to be read-only from inspect
+	snap := &Snapshot{Menus: []Menu{{Path: "/ip/address", Items: []Item{
+		{"address": "192.168.88.1/24", "network": "192.168.88.0", "interface": "bridge",
+			"actual-interface": "bridge-lan", "vrf": "lan", "invalid": "true", "slave": "false", "disabled": "false", "comment": "lan"},
+		{"address": "10.0.0.2/30", "network": "10.0.0.0", "interface": "ether1",
+			"actual-interface": "ether1", "vrf": "main", "invalid": "false", "slave": "false", "disabled": "false", "comment": ""},
+	}}}}
+
+	r, err := Restore(context.Background(), c, snap, RestoreOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"/ip/address/set", "=.id=*1", "=comment=lan"},
+		{"/ip/address/add", "=address=10.0.0.2/30", "=disabled=false", "=interface=ether1"},
+	}, r.Commands)
+	require.Len(t, r.Conflicts, 1)
+	require.Equal(t, []FieldChange{{Name: "comment", Old: "defconf", New: "lan"}}, r.Conflicts[0].Fields)
+}
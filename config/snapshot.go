@@ -0,0 +1,151 @@
+/*
+Package config reads the configuration of RouterOS devices into snapshots, compares
+snapshots taken from two devices (or from the same device at two points in time), and
+restores snapshots to devices.
+
+Snapshots can be saved as JSON or YAML, with attributes sorted by name so that they can be
+kept under version control.
+*/
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// Item is one entry of a menu, with the attributes as sent by the API.
+type Item map[string]string
+
+// Menu is the content of one menu path, e.g. /ip/address, in the order of the device.
+type Menu struct {
+	Path  string `json:"path" yaml:"path"`
+	Items []Item `json:"items" yaml:"items"`
+}
+
+// Snapshot is the content of a set of menus.
+type Snapshot struct {
+	Menus []Menu `json:"menus" yaml:"menus"`
+}
+
+// Menu returns the menu at path, or nil if the snapshot does not have it.
+func (s *Snapshot) Menu(path string) *Menu {
+	for i := range s.Menus {
+		if s.Menus[i].Path == path {
+			return &s.Menus[i]
+		}
+	}
+	return nil
+}
+
+// Read takes a snapshot of the menus at paths (e.g. "/ip/address"). The API print command
+// returns the same attributes as print detail does on the console.
+func Read(ctx context.Context, c *routeros.Client, paths ...string) (*Snapshot, error) {
+	s := &Snapshot{Menus: make([]Menu, 0, len(paths))}
+	for _, path := range paths {
+		r, err := c.RunContext(ctx, path+"/print")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		m := Menu{Path: path, Items: make([]Item, 0, len(r.Re))}
+		for _, sen := range r.Re {
+			item := make(Item, len(sen.List))
+			for _, p := range sen.List {
+				item[p.Key] = p.Value
+			}
+			m.Items = append(m.Items, item)
+		}
+		s.Menus = append(s.Menus, m)
+	}
+	return s, nil
+}
+
+// TakeOptions configure Take.
+type TakeOptions struct {
+	// IncludeDynamic keeps the items created by the device on its own.
+	IncludeDynamic bool
+	// IncludeDefault keeps the default and built-in items, which every device has.
+	IncludeDefault bool
+}
+
+// Take takes a snapshot of the menus at paths meant to be saved and restored: dynamic and
+// default items are left out, and so are the attributes in DefaultIgnore.
+func Take(ctx context.Context, c *routeros.Client, paths []string, opts TakeOptions) (*Snapshot, error) {
+	s, err := Read(ctx, c, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := make(map[string]bool, len(DefaultIgnore))
+	for _, k := range DefaultIgnore {
+		ignore[k] = true
+	}
+
+	for i := range s.Menus {
+		items := s.Menus[i].Items[:0]
+		for _, item := range s.Menus[i].Items {
+			if !opts.IncludeDynamic && isTrue(item["dynamic"]) {
+				continue
+			}
+			if !opts.IncludeDefault && (isTrue(item["default"]) || isTrue(item["builtin"])) {
+				continue
+			}
+
+			for k := range item {
+				if ignore[k] {
+					delete(item, k)
+				}
+			}
+			items = append(items, item)
+		}
+		s.Menus[i].Items = items
+	}
+	return s, nil
+}
+
+func isTrue(s string) bool {
+	b, _ := value.ParseBool(s)
+	return b
+}
+
+// WriteJSON writes s to w as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadJSON reads a snapshot written by WriteJSON.
+func ReadJSON(r io.Reader) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteYAML writes s to w as YAML.
+func (s *Snapshot) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadYAML reads a snapshot written by WriteYAML.
+func ReadYAML(r io.Reader) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := yaml.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestRead(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/address/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*1", "=address=192.168.88.1/24", "=interface=bridge", "=dynamic=false")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/identity/print @ []")
+		s.WriteSentence(t, "!re", "=name=router")
+		s.WriteSentence(t, "!done")
+	})
+
+	snap, err := Read(context.Background(), c, "/ip/address", "/system/identity")
+	require.NoError(t, err)
+	require.Equal(t, &Snapshot{Menus: []Menu{
+		{Path: "/ip/address", Items: []Item{{".id": "*1", "address": "192.168.88.1/24", "interface": "bridge", "dynamic": "false"}}},
+		{Path: "/system/identity", Items: []Item{{"name": "router"}}},
+	}}, snap)
+
+	require.Nil(t, snap.Menu("/ip/route"))
+	require.Equal(t, "router", snap.Menu("/system/identity").Items[0]["name"])
+}
+
+func TestReadError(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/nope/print @ []")
+		s.WriteSentence(t, "!trap", "=message=no such command prefix")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := Read(context.Background(), c, "/nope")
+	require.EqualError(t, err, "/nope: from RouterOS device: no such command prefix")
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	snap := &Snapshot{Menus: []Menu{
+		{Path: "/interface", Items: []Item{{"name": "ether1", "mtu": "1500"}, {"name": "ether2"}}},
+		{Path: "/ip/pool", Items: []Item{}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, snap.WriteJSON(&buf))
+
+	got, err := ReadJSON(&buf)
+	require.NoError(t, err)
+	require.Equal(t, snap, got)
+}
+
+func TestTake(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ppp/profile/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*0", "=name=default", "=default=true")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=vpn", "=local-address=10.0.0.1", "=default=false")
+		s.WriteSentence(t, "!re", "=.id=*2", "=name=<pptp-1>", "=dynamic=true")
+		s.WriteSentence(t, "!done")
+	})
+
+	snap, err := Take(context.Background(), c, []string{"/ppp/profile"}, TakeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &Snapshot{Menus: []Menu{
+		{Path: "/ppp/profile", Items: []Item{{"name": "vpn", "local-address": "10.0.0.1", "default": "false"}}},
+	}}, snap)
+}
+
+func TestSnapshotYAML(t *testing.T) {
+	snap := &Snapshot{Menus: []Menu{
+		{Path: "/system/identity", Items: []Item{{"name": "router"}}},
+		{Path: "/ip/address", Items: []Item{{"address": "192.168.88.1/24", "interface": "bridge", "comment": "yes"}}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, snap.WriteYAML(&buf))
+	require.Equal(t, `menus:
+  - path: /system/identity
+    items:
+      - name: router
+  - path: /ip/address
+    items:
+      - address: 192.168.88.1/24
+        comment: "yes"
+        interface: bridge
+`, buf.String())
+
+	got, err := ReadYAML(&buf)
+	require.NoError(t, err)
+	require.Equal(t, snap, got)
+}
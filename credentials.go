@@ -0,0 +1,201 @@
+package routeros
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3/tlsconfig"
+)
+
+// ErrNoCredentials is returned when a Credentials provider has nothing to give.
+var ErrNoCredentials = errors.New("no credentials found")
+
+// Credentials provide the user name and password to log in with. Dialer fetches them for every
+// connection, so secrets rotated in their source are picked up when reconnecting.
+type Credentials interface {
+	Credentials(ctx context.Context) (username, password string, err error)
+}
+
+// CredentialsFunc adapts a function, e.g. reading from a vault, to Credentials.
+type CredentialsFunc func(ctx context.Context) (username, password string, err error)
+
+// Credentials implements Credentials.
+func (f CredentialsFunc) Credentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// StaticCredentials returns Credentials that always give username and password.
+func StaticCredentials(username, password string) Credentials {
+	return CredentialsFunc(func(context.Context) (string, string, error) {
+		return username, password, nil
+	})
+}
+
+// EnvCredentials returns Credentials read from the environment variables userVar and passVar.
+// Both must be set, but the password may be empty.
+func EnvCredentials(userVar, passVar string) Credentials {
+	return CredentialsFunc(func(context.Context) (string, string, error) {
+		username, ok := os.LookupEnv(userVar)
+		if !ok {
+			return "", "", fmt.Errorf("%w: %s is not set", ErrNoCredentials, userVar)
+		}
+		password, ok := os.LookupEnv(passVar)
+		if !ok {
+			return "", "", fmt.Errorf("%w: %s is not set", ErrNoCredentials, passVar)
+		}
+		return username, password, nil
+	})
+}
+
+// FileCredentials returns Credentials with the password read from the file at path, such as a
+// Docker or Kubernetes secret. A trailing line break is not part of the password.
+func FileCredentials(username, path string) Credentials {
+	return CredentialsFunc(func(context.Context) (string, string, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("read password: %w", err)
+		}
+		return username, strings.TrimRight(string(b), "\r\n"), nil
+	})
+}
+
+// NetrcCredentials returns Credentials read from the netrc file at path, from the entry of
+// machine or else the default one. An empty path means $NETRC, or .netrc in the home directory.
+func NetrcCredentials(path, machine string) Credentials {
+	return CredentialsFunc(func(context.Context) (string, string, error) {
+		path := path
+		if path == "" {
+			path = os.Getenv("NETRC")
+		}
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", fmt.Errorf("netrc: %w", err)
+			}
+			path = filepath.Join(home, ".netrc")
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return "", "", fmt.Errorf("netrc: %w", err)
+		}
+		defer f.Close()
+
+		username, password, found, err := parseNetrc(bufio.NewScanner(f), machine)
+		if err != nil {
+			return "", "", fmt.Errorf("netrc %s: %w", path, err)
+		}
+		if !found {
+			return "", "", fmt.Errorf("%w: no entry for %s in %s", ErrNoCredentials, machine, path)
+		}
+		return username, password, nil
+	})
+}
+
+// parseNetrc returns the login and password of machine, or of the default entry, which
+// applies only if no machine matched before it. Macro definitions are skipped.
+func parseNetrc(sc *bufio.Scanner, machine string) (username, password string, found bool, err error) {
+	// matching tells whether the tokens read belong to the entry wanted
+	var matching, inMacro bool
+	for sc.Scan() {
+		line := sc.Text()
+		if inMacro {
+			// a macro ends at the first empty line
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			value := func() string {
+				if i+1 < len(fields) {
+					i++
+					return fields[i]
+				}
+				return ""
+			}
+
+			switch fields[i] {
+			case "machine":
+				if found {
+					return username, password, true, nil
+				}
+				matching = value() == machine
+				found = matching
+			case "default":
+				if found {
+					return username, password, true, nil
+				}
+				matching, found = true, true
+			case "login":
+				if v := value(); matching {
+					username = v
+				}
+			case "password":
+				if v := value(); matching {
+					password = v
+				}
+			case "account":
+				value()
+			case "macdef":
+				value()
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return username, password, found, sc.Err()
+}
+
+// LoginCredentials fetches the credentials from creds and runs the /login command.
+func (c *Client) LoginCredentials(ctx context.Context, creds Credentials) error {
+	username, password, err := creds.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("credentials: %w", err)
+	}
+	return c.LoginContext(ctx, username, password)
+}
+
+// Dialer connects to a device and logs in with Credentials fetched for every connection. Its
+// DialContext method fits the Dial options of the retry and safemode packages.
+type Dialer struct {
+	// Address is the host and port of the device, e.g. "192.168.88.1:8728".
+	Address     string
+	Credentials Credentials
+	// TLSConfig makes the connection use TLS when not nil.
+	TLSConfig *tls.Config
+	// DialConn, if set, opens the connection instead, and TLSConfig is not used. It may go
+	// through a proxy, or be tlsconfig.DialAnonymousDH or tlsconfig.AnonymousDHFallback for an
+	// api-ssl service without certificate.
+	DialConn func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DialContext connects and logs in to the device.
+func (d *Dialer) DialContext(ctx context.Context) (*Client, error) {
+	username, password, err := d.Credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("credentials: %w", err)
+	}
+
+	var conn net.Conn
+	switch {
+	case d.DialConn != nil:
+		conn, err = d.DialConn(ctx, "tcp", d.Address)
+	case d.TLSConfig != nil:
+		conn, err = (&tls.Dialer{Config: d.TLSConfig}).DialContext(ctx, "tcp", d.Address)
+		err = tlsconfig.CheckAnonymousDH(err)
+	default:
+		conn, err = new(net.Dialer).DialContext(ctx, "tcp", d.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to router os: %w", err)
+	}
+	return newClientAndLogin(ctx, conn, username, password)
+}
@@ -0,0 +1,163 @@
+package routeros
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+func TestLoginCredentials(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	var buf bytes.Buffer
+	c.SetLogHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	go func() {
+		defer deferCloser(t, s)
+		sen, err := s.r.ReadCommand()
+		require.NoError(t, err)
+		require.Equal(t, "userTest", sen.Map["name"])
+		require.Equal(t, "passTest", sen.Map["password"])
+		s.writeSentence(t, "!done")
+	}()
+
+	err := c.LoginCredentials(context.Background(), StaticCredentials("userTest", "passTest"))
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "passTest")
+	require.Contains(t, buf.String(), "=password=***")
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("ROS_TEST_USER", "admin")
+	creds := EnvCredentials("ROS_TEST_USER", "ROS_TEST_PASSWORD")
+
+	_, _, err := creds.Credentials(context.Background())
+	require.ErrorIs(t, err, ErrNoCredentials)
+
+	t.Setenv("ROS_TEST_PASSWORD", "")
+	username, password, err := creds.Credentials(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "admin", username)
+	require.Equal(t, "", password)
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cret\n"), 0o600))
+
+	username, password, err := FileCredentials("admin", path).Credentials(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "admin", username)
+	require.Equal(t, "s3cret", password)
+}
+
+func TestNetrcCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(path, []byte(`machine router1 login admin password one
+macdef init
+machine router2 login macro password macro
+
+machine router2
+  login ops
+  password two
+default login guest password guest
+`), 0o600))
+
+	for _, tc := range []struct {
+		machine, username, password string
+	}{
+		{"router1", "admin", "one"},
+		{"router2", "ops", "two"},
+		{"router3", "guest", "guest"},
+	} {
+		t.Run(tc.machine, func(t *testing.T) {
+			username, password, err := NetrcCredentials(path, tc.machine).Credentials(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tc.username, username)
+			require.Equal(t, tc.password, password)
+		})
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("machine router1 login admin password one\n"), 0o600))
+	_, _, err := NetrcCredentials(path, "router2").Credentials(context.Background())
+	require.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestDialerFetchesCredentials(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		for i := 1; ; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			sen, err := proto.NewReader(conn).ReadSentence()
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("pass%d", i), sen.Map["password"])
+
+			w := proto.NewWriter(conn)
+			w.BeginSentence()
+			w.WriteWord("!done")
+			require.NoError(t, w.EndSentence())
+		}
+	}()
+
+	var fetched int
+	d := &Dialer{
+		Address: ln.Addr().String(),
+		Credentials: CredentialsFunc(func(context.Context) (string, string, error) {
+			fetched++
+			return "admin", fmt.Sprintf("pass%d", fetched), nil
+		}),
+	}
+
+	for i := 0; i < 2; i++ {
+		c, err := d.DialContext(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+	}
+	require.Equal(t, 2, fetched)
+}
+
+func TestDialerDialConn(t *testing.T) {
+	a, b := net.Pipe()
+
+	go func() {
+		defer b.Close()
+		sen, err := proto.NewReader(b).ReadSentence()
+		require.NoError(t, err)
+		require.Equal(t, "secret", sen.Map["password"])
+
+		w := proto.NewWriter(b)
+		w.BeginSentence()
+		w.WriteWord("!done")
+		require.NoError(t, w.EndSentence())
+	}()
+
+	d := &Dialer{
+		Address:     "192.0.2.1:8729",
+		Credentials: StaticCredentials("admin", "secret"),
+		DialConn: func(_ context.Context, network, address string) (net.Conn, error) {
+			require.Equal(t, "tcp", network)
+			require.Equal(t, "192.0.2.1:8729", address)
+			return a, nil
+		},
+	}
+
+	c, err := d.DialContext(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+}
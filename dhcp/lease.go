@@ -0,0 +1,238 @@
+/*
+Package dhcp is a typed client for the leases of the RouterOS DHCP server (/ip/dhcp-server/lease).
+*/
+package dhcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+const leasePath = "/ip/dhcp-server/lease"
+
+// Lease is an entry of /ip/dhcp-server/lease.
+type Lease struct {
+	ID         string
+	Server     string
+	Address    netip.Addr
+	MACAddress net.HardwareAddr
+	ClientID   string
+	HostName   string
+	Comment    string
+	// Status is e.g. waiting, offered or bound.
+	Status string
+
+	ActiveAddress    netip.Addr
+	ActiveMACAddress net.HardwareAddr
+
+	// ExpiresAfter is the time left until a bound lease expires.
+	ExpiresAfter time.Duration
+	// LastSeen is the time since the client was last seen, zero if never.
+	LastSeen time.Duration
+
+	Dynamic  bool
+	Disabled bool
+	Blocked  bool
+}
+
+// Client manages DHCP server leases through a routeros.Client.
+type Client struct {
+	c *routeros.Client
+}
+
+// New returns a Client using c.
+func New(c *routeros.Client) *Client {
+	return &Client{c: c}
+}
+
+// List returns all the leases.
+func (c *Client) List(ctx context.Context) ([]Lease, error) {
+	r, err := c.c.RunContext(ctx, leasePath+"/print")
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]Lease, 0, len(r.Re))
+	for _, sen := range r.Re {
+		l, err := parseLease(sen)
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, l)
+	}
+	return leases, nil
+}
+
+// MakeStatic turns the dynamic leases with the given ids into static ones.
+func (c *Client) MakeStatic(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := c.c.RunContext(ctx, leasePath+"/make-static", "=numbers="+strings.Join(ids, ","))
+	return err
+}
+
+// AddStatic adds a static lease of address to mac. An empty server makes the lease valid on all
+// the servers. It returns the id of the new lease.
+func (c *Client) AddStatic(ctx context.Context, server string, address netip.Addr, mac net.HardwareAddr, comment string) (string, error) {
+	args := []string{leasePath + "/add", "=address=" + address.String(), "=mac-address=" + value.FormatMAC(mac)}
+	if server != "" {
+		args = append(args, "=server="+server)
+	}
+	if comment != "" {
+		args = append(args, "=comment="+comment)
+	}
+
+	r, err := c.c.RunArgsContext(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	// Done is nil if the client was closed while the command ran
+	if r.Done == nil {
+		return "", fmt.Errorf("add lease %s: %w", address, io.ErrUnexpectedEOF)
+	}
+	return r.Done.Map["ret"], nil
+}
+
+// Remove removes the leases with the given ids.
+func (c *Client) Remove(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := c.c.RunContext(ctx, leasePath+"/remove", "=numbers="+strings.Join(ids, ","))
+	return err
+}
+
+// EventType tells what happened to a lease.
+type EventType int
+
+const (
+	Added EventType = iota
+	Changed
+	Removed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Changed:
+		return "changed"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is a change of a lease reported by WatchLeases.
+type Event struct {
+	Type EventType
+	// Lease is the lease after the change. For Removed events it is the last known state of the
+	// lease, or just its ID if it was never seen before.
+	Lease Lease
+}
+
+// LeaseWatcher is a running watch of the leases.
+type LeaseWatcher struct {
+	*stream.Stream[Event]
+}
+
+// WatchLeases reports the changes of the leases until ctx is done or Cancel is called. It starts
+// listening before reading the current leases, so that no change is lost in between: the changes
+// of existing leases are reported as Changed, and those made while the leases are read may be
+// reported although the leases read already show them.
+func (c *Client) WatchLeases(ctx context.Context) (*LeaseWatcher, error) {
+	// written before ready is closed, then only used by parse
+	known := make(map[string]Lease)
+	ready := make(chan struct{})
+
+	parse := func(sen *proto.Sentence) (Event, error) {
+		if sen.IsDead() {
+			l, ok := known[sen.ID()]
+			if !ok {
+				l = Lease{ID: sen.ID()}
+			}
+			delete(known, sen.ID())
+			return Event{Type: Removed, Lease: l}, nil
+		}
+
+		l, err := parseLease(sen)
+		if err != nil {
+			return Event{}, err
+		}
+
+		t := Added
+		if _, ok := known[l.ID]; ok {
+			t = Changed
+		}
+		known[l.ID] = l
+		return Event{Type: t, Lease: l}, nil
+	}
+
+	s, err := stream.ListenAfter(ctx, c.c, []string{leasePath + "/listen"}, c.c.Queue, ready, parse)
+	if err != nil {
+		return nil, err
+	}
+
+	leases, err := c.List(ctx)
+	if err != nil {
+		s.Cancel()
+		return nil, err
+	}
+	for _, l := range leases {
+		known[l.ID] = l
+	}
+	close(ready)
+
+	return &LeaseWatcher{s}, nil
+}
+
+func parseLease(sen *proto.Sentence) (Lease, error) {
+	p := attrs.New(sen.Map)
+	l := Lease{
+		ID:         sen.ID(),
+		Server:     p.String("server"),
+		Address:    p.Addr("address"),
+		MACAddress: p.MAC("mac-address"),
+		ClientID:   p.String("client-id"),
+		HostName:   p.String("host-name"),
+		Comment:    p.String("comment"),
+		Status:     p.String("status"),
+
+		ActiveAddress:    p.Addr("active-address"),
+		ActiveMACAddress: p.MAC("active-mac-address"),
+
+		ExpiresAfter: optionalDuration(p, "expires-after"),
+		LastSeen:     optionalDuration(p, "last-seen"),
+
+		Dynamic:  p.Bool("dynamic"),
+		Disabled: p.Bool("disabled"),
+		Blocked:  p.Bool("blocked"),
+	}
+	if err := p.Err(); err != nil {
+		return Lease{}, fmt.Errorf("lease %s: %w", l.ID, err)
+	}
+	return l, nil
+}
+
+// optionalDuration parses a duration which may also be "never".
+func optionalDuration(p *attrs.Parser, key string) time.Duration {
+	if p.String(key) == "never" {
+		return 0
+	}
+	return p.Duration(key)
+}
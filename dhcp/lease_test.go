@@ -0,0 +1,143 @@
+package dhcp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func mustMAC(t *testing.T, s string) net.HardwareAddr {
+	mac, err := net.ParseMAC(s)
+	require.NoError(t, err)
+	return mac
+}
+
+func TestList(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/dhcp-server/lease/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*1", "=address=192.168.88.10", "=mac-address=4C:5E:0C:12:34:56", "=server=lan",
+			"=host-name=laptop", "=status=bound", "=expires-after=9m45s", "=last-seen=15s", "=dynamic=true", "=disabled=false")
+		s.WriteSentence(t, "!re", "=.id=*2", "=address=192.168.88.2", "=mac-address=4C:5E:0C:00:00:02",
+			"=status=waiting", "=last-seen=never", "=dynamic=false", "=comment=printer")
+		s.WriteSentence(t, "!done")
+	})
+
+	leases, err := New(c).List(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Lease{
+		{
+			ID:           "*1",
+			Server:       "lan",
+			Address:      netip.MustParseAddr("192.168.88.10"),
+			MACAddress:   mustMAC(t, "4C:5E:0C:12:34:56"),
+			HostName:     "laptop",
+			Status:       "bound",
+			ExpiresAfter: 9*time.Minute + 45*time.Second,
+			LastSeen:     15 * time.Second,
+			Dynamic:      true,
+		},
+		{
+			ID:         "*2",
+			Address:    netip.MustParseAddr("192.168.88.2"),
+			MACAddress: mustMAC(t, "4C:5E:0C:00:00:02"),
+			Comment:    "printer",
+			Status:     "waiting",
+		},
+	}, leases)
+}
+
+func TestChanges(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/dhcp-server/lease/make-static @ [{`numbers` `*1,*3`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/dhcp-server/lease/add @ [{`address` `192.168.88.5`} {`mac-address` `4C:5E:0C:00:00:05`} {`server` `lan`} {`comment` `nas`}]")
+		s.WriteSentence(t, "!done", "=ret=*7")
+		s.ReadSentence(t, "/ip/dhcp-server/lease/remove @ [{`numbers` `*7`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	ctx := context.Background()
+	dc := New(c)
+	require.NoError(t, dc.MakeStatic(ctx, "*1", "*3"))
+
+	id, err := dc.AddStatic(ctx, "lan", netip.MustParseAddr("192.168.88.5"), mustMAC(t, "4c:5e:0c:00:00:05"), "nas")
+	require.NoError(t, err)
+	require.Equal(t, "*7", id)
+
+	require.NoError(t, dc.Remove(ctx, id))
+}
+
+func TestAddStaticClosed(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	c.Async()
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/dhcp-server/lease/add @r1 [{`address` `192.168.88.5`} {`mac-address` `4C:5E:0C:00:00:05`}]")
+		// let the write of the command return before closing
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, c.Close())
+	})
+
+	_, err := New(c).AddStatic(context.Background(), "", netip.MustParseAddr("192.168.88.5"), mustMAC(t, "4c:5e:0c:00:00:05"), "")
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestWatchLeases(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/dhcp-server/lease/listen @l1 []")
+		s.ReadSentence(t, "/ip/dhcp-server/lease/print @r2 []")
+		// a change made while the leases are read is held back until they are
+		s.WriteSentence(t, "!re", ".tag=l1", "=.id=*1", "=address=192.168.88.10", "=mac-address=4C:5E:0C:12:34:56", "=status=bound", "=expires-after=10m")
+		s.WriteSentence(t, "!re", ".tag=r2", "=.id=*1", "=address=192.168.88.10", "=mac-address=4C:5E:0C:12:34:56", "=status=waiting")
+		s.WriteSentence(t, "!done", ".tag=r2")
+
+		s.WriteSentence(t, "!re", ".tag=l1", "=.id=*2", "=address=192.168.88.11", "=mac-address=4C:5E:0C:00:00:11", "=status=bound")
+		s.WriteSentence(t, "!re", ".tag=l1", "=.id=*1", "=.dead=yes")
+		s.WriteSentence(t, "!re", ".tag=l1", "=.id=*9", "=.dead=true")
+		s.ReadSentence(t, "/cancel @r3 [{`tag` `l1`}]")
+		s.WriteSentence(t, "!trap", "=category=2", "=message=interrupted", ".tag=l1")
+		s.WriteSentence(t, "!done", ".tag=r3")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := New(c).WatchLeases(ctx)
+	require.NoError(t, err)
+
+	ev := <-w.C()
+	require.Equal(t, Changed, ev.Type)
+	require.Equal(t, "bound", ev.Lease.Status)
+	require.Equal(t, 10*time.Minute, ev.Lease.ExpiresAfter)
+
+	ev = <-w.C()
+	require.Equal(t, Added, ev.Type)
+	require.Equal(t, netip.MustParseAddr("192.168.88.11"), ev.Lease.Address)
+
+	ev = <-w.C()
+	require.Equal(t, Removed, ev.Type)
+	require.Equal(t, "*1", ev.Lease.ID)
+	require.Equal(t, "bound", ev.Lease.Status, "removed events carry the last known lease")
+
+	ev = <-w.C()
+	require.Equal(t, Event{Type: Removed, Lease: Lease{ID: "*9"}}, ev)
+
+	cancel()
+	for range w.C() {
+	}
+	require.ErrorIs(t, w.Err(), context.Canceled)
+}
@@ -0,0 +1,113 @@
+package diag
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// BandwidthTestOptions configure BandwidthTest. Zero fields use the device defaults.
+type BandwidthTestOptions struct {
+	User     string
+	Password string
+	// Direction is receive, transmit or both.
+	Direction string
+	// Protocol is tcp or udp.
+	Protocol        string
+	ConnectionCount int
+	LocalTxSpeed    uint64
+	RemoteTxSpeed   uint64
+	// Duration stops the test after the given time. Zero runs it until canceled.
+	Duration time.Duration
+}
+
+func (o BandwidthTestOptions) args(address string) []string {
+	args := []string{"/tool/bandwidth-test", "=address=" + address}
+	if o.User != "" {
+		args = append(args, "=user="+o.User)
+	}
+	if o.Password != "" {
+		args = append(args, "=password="+o.Password)
+	}
+	if o.Direction != "" {
+		args = append(args, "=direction="+o.Direction)
+	}
+	if o.Protocol != "" {
+		args = append(args, "=protocol="+o.Protocol)
+	}
+	if o.ConnectionCount > 0 {
+		args = append(args, "=connection-count="+strconv.Itoa(o.ConnectionCount))
+	}
+	if o.LocalTxSpeed > 0 {
+		args = append(args, "=local-tx-speed="+value.FormatRate(o.LocalTxSpeed))
+	}
+	if o.RemoteTxSpeed > 0 {
+		args = append(args, "=remote-tx-speed="+value.FormatRate(o.RemoteTxSpeed))
+	}
+	if o.Duration > 0 {
+		args = append(args, "=duration="+value.FormatDuration(o.Duration))
+	}
+	return args
+}
+
+// BandwidthSample is the state of a bandwidth test, reported every second. Rates are in bits per second.
+type BandwidthSample struct {
+	// Status is e.g. connecting, running or done testing.
+	Status   string
+	Duration time.Duration
+
+	TxCurrent         uint64
+	Tx10SecondAverage uint64
+	TxTotalAverage    uint64
+	RxCurrent         uint64
+	Rx10SecondAverage uint64
+	RxTotalAverage    uint64
+	LostPackets       uint64
+	ConnectionCount   int
+	LocalCPULoad      int
+	RemoteCPULoad     int
+}
+
+// BandwidthTestStream is a running /tool/bandwidth-test.
+type BandwidthTestStream struct {
+	*stream.Stream[BandwidthSample]
+}
+
+// BandwidthTest runs a bandwidth test against the bandwidth test server at address.
+func BandwidthTest(ctx context.Context, c *routeros.Client, address string, opts BandwidthTestOptions) (*BandwidthTestStream, error) {
+	s, err := stream.Listen(ctx, c, opts.args(address), c.Queue, parseBandwidthSample)
+	if err != nil {
+		return nil, err
+	}
+	return &BandwidthTestStream{s}, nil
+}
+
+func parseBandwidthSample(sen *proto.Sentence) (BandwidthSample, error) {
+	p := attrs.New(sen.Map)
+	s := BandwidthSample{
+		Status:   p.String("status"),
+		Duration: p.Duration("duration"),
+
+		TxCurrent:         p.Rate("tx-current"),
+		Tx10SecondAverage: p.Rate("tx-10-second-average"),
+		TxTotalAverage:    p.Rate("tx-total-average"),
+		RxCurrent:         p.Rate("rx-current"),
+		Rx10SecondAverage: p.Rate("rx-10-second-average"),
+		RxTotalAverage:    p.Rate("rx-total-average"),
+		LostPackets:       p.Uint("lost-packets"),
+		ConnectionCount:   int(p.Int("connection-count")),
+		LocalCPULoad:      percent(p, "local-cpu-load"),
+		RemoteCPULoad:     percent(p, "remote-cpu-load"),
+	}
+	if err := p.Err(); err != nil {
+		return BandwidthSample{}, fmt.Errorf("bandwidth test: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,55 @@
+package diag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestBandwidthTest(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/tool/bandwidth-test @l1 [{`address` `192.0.2.1`} {`user` `admin`} {`password` `secret`} "+
+			"{`direction` `both`} {`protocol` `udp`} {`remote-tx-speed` `100M`} {`duration` `2s`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=connecting")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=running", "=duration=1s", "=tx-current=95.2Mbps", "=rx-current=90000000",
+			"=tx-total-average=95200000", "=lost-packets=3", "=connection-count=1", "=local-cpu-load=12%", "=remote-cpu-load=30%")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=done testing", "=duration=2s")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	bt, err := BandwidthTest(context.Background(), c, "192.0.2.1", BandwidthTestOptions{
+		User:          "admin",
+		Password:      "secret",
+		Direction:     "both",
+		Protocol:      "udp",
+		RemoteTxSpeed: 100e6,
+		Duration:      2 * time.Second,
+	})
+	require.NoError(t, err)
+
+	var samples []BandwidthSample
+	for sample := range bt.C() {
+		samples = append(samples, sample)
+	}
+	require.NoError(t, bt.Err())
+	require.Len(t, samples, 3)
+
+	require.Equal(t, BandwidthSample{
+		Status:          "running",
+		Duration:        time.Second,
+		TxCurrent:       95200000,
+		RxCurrent:       90000000,
+		TxTotalAverage:  95200000,
+		LostPackets:     3,
+		ConnectionCount: 1,
+		LocalCPULoad:    12,
+		RemoteCPULoad:   30,
+	}, samples[1])
+	require.Equal(t, "done testing", samples[2].Status)
+}
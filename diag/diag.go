@@ -0,0 +1,29 @@
+/*
+Package diag runs the diagnostic tools of RouterOS devices (ping, traceroute, torch and
+bandwidth-test) and delivers their results as typed values.
+
+Every tool runs until its count or duration limit is reached, or until the context is done or
+Cancel is called, in which case /cancel is sent so the tool stops on the device too.
+*/
+package diag
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+)
+
+// percent parses values such as 12%.
+func percent(p *attrs.Parser, key string) int {
+	s := strings.TrimSuffix(p.String(key), "%")
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		// let the parser record the error
+		return int(p.Int(key))
+	}
+	return n
+}
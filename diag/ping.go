@@ -0,0 +1,105 @@
+package diag
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// PingOptions configure Ping. Zero fields use the device defaults.
+type PingOptions struct {
+	// Count is the number of packets to send. Zero pings until canceled.
+	Count      int
+	Size       int
+	Interval   time.Duration
+	Interface  string
+	SrcAddress netip.Addr
+	// RoutingTable is the routing table (routing-table on RouterOS 7) to use.
+	RoutingTable string
+}
+
+func (o PingOptions) args(address string) []string {
+	args := []string{"/ping", "=address=" + address}
+	if o.Count > 0 {
+		args = append(args, "=count="+strconv.Itoa(o.Count))
+	}
+	if o.Size > 0 {
+		args = append(args, "=size="+strconv.Itoa(o.Size))
+	}
+	if o.Interval > 0 {
+		args = append(args, "=interval="+value.FormatDuration(o.Interval))
+	}
+	if o.Interface != "" {
+		args = append(args, "=interface="+o.Interface)
+	}
+	if o.SrcAddress.IsValid() {
+		args = append(args, "=src-address="+o.SrcAddress.String())
+	}
+	if o.RoutingTable != "" {
+		args = append(args, "=routing-table="+o.RoutingTable)
+	}
+	return args
+}
+
+// PingReply is the result of one echo request, along with the running statistics.
+type PingReply struct {
+	Seq  int
+	Host netip.Addr
+	Size int
+	TTL  int
+	RTT  time.Duration
+	// Status is empty for replies, and e.g. "timeout" or "host unreachable" otherwise.
+	Status string
+
+	Sent       int
+	Received   int
+	PacketLoss int
+	MinRTT     time.Duration
+	AvgRTT     time.Duration
+	MaxRTT     time.Duration
+}
+
+// PingStream is a running /ping.
+type PingStream struct {
+	*stream.Stream[PingReply]
+}
+
+// Ping pings address, which may be an IP address or a host name.
+func Ping(ctx context.Context, c *routeros.Client, address string, opts PingOptions) (*PingStream, error) {
+	s, err := stream.Listen(ctx, c, opts.args(address), c.Queue, parsePingReply)
+	if err != nil {
+		return nil, err
+	}
+	return &PingStream{s}, nil
+}
+
+func parsePingReply(sen *proto.Sentence) (PingReply, error) {
+	p := attrs.New(sen.Map)
+	r := PingReply{
+		Seq:    int(p.Int("seq")),
+		Host:   p.Addr("host"),
+		Size:   int(p.Int("size")),
+		TTL:    int(p.Int("ttl")),
+		RTT:    p.Duration("time"),
+		Status: p.String("status"),
+
+		Sent:       int(p.Int("sent")),
+		Received:   int(p.Int("received")),
+		PacketLoss: percent(p, "packet-loss"),
+		MinRTT:     p.Duration("min-rtt"),
+		AvgRTT:     p.Duration("avg-rtt"),
+		MaxRTT:     p.Duration("max-rtt"),
+	}
+	if err := p.Err(); err != nil {
+		return PingReply{}, fmt.Errorf("ping reply %d: %w", r.Seq, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,71 @@
+package diag
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestPing(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ping @l1 [{`address` `192.0.2.1`} {`count` `2`} {`size` `100`} {`interval` `500ms`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=seq=0", "=host=192.0.2.1", "=size=100", "=ttl=64", "=time=12ms345us",
+			"=sent=1", "=received=1", "=packet-loss=0", "=min-rtt=12ms345us", "=avg-rtt=12ms345us", "=max-rtt=12ms345us")
+		s.WriteSentence(t, "!re", ".tag=l1", "=seq=1", "=host=192.0.2.1", "=status=timeout",
+			"=sent=2", "=received=1", "=packet-loss=50", "=min-rtt=12ms345us", "=avg-rtt=12ms345us", "=max-rtt=12ms345us")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	p, err := Ping(context.Background(), c, "192.0.2.1", PingOptions{Count: 2, Size: 100, Interval: 500 * time.Millisecond})
+	require.NoError(t, err)
+
+	var replies []PingReply
+	for r := range p.C() {
+		replies = append(replies, r)
+	}
+	require.NoError(t, p.Err())
+	require.Len(t, replies, 2)
+
+	rtt := 12*time.Millisecond + 345*time.Microsecond
+	require.Equal(t, PingReply{
+		Seq: 0, Host: netip.MustParseAddr("192.0.2.1"), Size: 100, TTL: 64, RTT: rtt,
+		Sent: 1, Received: 1, MinRTT: rtt, AvgRTT: rtt, MaxRTT: rtt,
+	}, replies[0])
+	require.Equal(t, "timeout", replies[1].Status)
+	require.Equal(t, 50, replies[1].PacketLoss)
+}
+
+func TestPingCancel(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ping @l1 [{`address` `example.com`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=seq=0", "=host=192.0.2.1", "=time=1ms")
+		s.ReadSentence(t, "/cancel @r2 [{`tag` `l1`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=seq=1", "=host=192.0.2.1", "=time=1ms")
+		s.WriteSentence(t, "!trap", "=category=2", "=message=interrupted", ".tag=l1")
+		s.WriteSentence(t, "!done", ".tag=r2")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p, err := Ping(ctx, c, "example.com", PingOptions{})
+	require.NoError(t, err)
+
+	r := <-p.C()
+	require.Equal(t, time.Millisecond, r.RTT)
+
+	cancel()
+	for range p.C() {
+	}
+	require.ErrorIs(t, p.Err(), context.Canceled)
+}
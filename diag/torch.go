@@ -0,0 +1,102 @@
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// TorchOptions configure Torch. The traffic is broken down by the attributes that are set;
+// use 0.0.0.0/0 as address to break it down by any address, and "any" as port for any port.
+type TorchOptions struct {
+	Interface  string
+	SrcAddress string
+	DstAddress string
+	Port       string
+	IPProtocol string
+	// Duration stops torch after the given time. Zero runs it until canceled.
+	Duration time.Duration
+}
+
+func (o TorchOptions) args() []string {
+	args := []string{"/tool/torch", "=interface=" + o.Interface}
+	if o.SrcAddress != "" {
+		args = append(args, "=src-address="+o.SrcAddress)
+	}
+	if o.DstAddress != "" {
+		args = append(args, "=dst-address="+o.DstAddress)
+	}
+	if o.Port != "" {
+		args = append(args, "=port="+o.Port)
+	}
+	if o.IPProtocol != "" {
+		args = append(args, "=ip-protocol="+o.IPProtocol)
+	}
+	if o.Duration > 0 {
+		args = append(args, "=duration="+value.FormatDuration(o.Duration))
+	}
+	return args
+}
+
+// Flow is the traffic of one line of torch. Attributes torch was not asked to break the
+// traffic down by are zero; the line with all of them zero is the total.
+type Flow struct {
+	SrcAddress  netip.Addr
+	DstAddress  netip.Addr
+	SrcPort     string
+	DstPort     string
+	IPProtocol  string
+	MACProtocol string
+
+	TxRate    uint64
+	RxRate    uint64
+	TxPackets uint64
+	RxPackets uint64
+}
+
+// TorchStream is a running /tool/torch. Every value is the whole flow table of one second.
+type TorchStream struct {
+	*stream.Stream[[]Flow]
+}
+
+// Torch starts monitoring the traffic of an interface.
+func Torch(ctx context.Context, c *routeros.Client, opts TorchOptions) (*TorchStream, error) {
+	if opts.Interface == "" {
+		return nil, errors.New("torch: interface is required")
+	}
+
+	s, err := stream.ListenSections(ctx, c, opts.args(), c.Queue, parseFlow)
+	if err != nil {
+		return nil, err
+	}
+	return &TorchStream{s}, nil
+}
+
+func parseFlow(sen *proto.Sentence) (Flow, error) {
+	p := attrs.New(sen.Map)
+	f := Flow{
+		SrcAddress:  p.Addr("src-address"),
+		DstAddress:  p.Addr("dst-address"),
+		SrcPort:     p.String("src-port"),
+		DstPort:     p.String("dst-port"),
+		IPProtocol:  p.String("ip-protocol"),
+		MACProtocol: p.String("mac-protocol"),
+
+		TxRate:    p.Rate("tx"),
+		RxRate:    p.Rate("rx"),
+		TxPackets: p.Uint("tx-packets"),
+		RxPackets: p.Uint("rx-packets"),
+	}
+	if err := p.Err(); err != nil {
+		return Flow{}, fmt.Errorf("torch flow: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,47 @@
+package diag
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestTorch(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/tool/torch @l1 [{`interface` `ether1`} {`src-address` `0.0.0.0/0`} {`duration` `2s`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=0", "=src-address=192.168.88.10", "=tx=1520", "=rx=30000", "=tx-packets=2", "=rx-packets=20")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=0", "=tx=1520", "=rx=30000", "=tx-packets=2", "=rx-packets=20")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=1", "=src-address=192.168.88.10", "=tx=0", "=rx=1.2M")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	torch, err := Torch(context.Background(), c, TorchOptions{Interface: "ether1", SrcAddress: "0.0.0.0/0", Duration: 2 * time.Second})
+	require.NoError(t, err)
+
+	flows := <-torch.C()
+	require.Equal(t, []Flow{
+		{SrcAddress: netip.MustParseAddr("192.168.88.10"), TxRate: 1520, RxRate: 30000, TxPackets: 2, RxPackets: 20},
+		{TxRate: 1520, RxRate: 30000, TxPackets: 2, RxPackets: 20},
+	}, flows)
+
+	flows = <-torch.C()
+	require.Equal(t, []Flow{{SrcAddress: netip.MustParseAddr("192.168.88.10"), RxRate: 1200000}}, flows)
+
+	_, ok := <-torch.C()
+	require.False(t, ok)
+	require.NoError(t, torch.Err())
+}
+
+func TestTorchNeedsInterface(t *testing.T) {
+	c, _ := routerostest.NewPair(t)
+
+	_, err := Torch(context.Background(), c, TorchOptions{})
+	require.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package diag
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// TracerouteOptions configure Traceroute. Zero fields use the device defaults.
+type TracerouteOptions struct {
+	// Count is the number of probes per hop. Zero traces until canceled.
+	Count        int
+	MaxHops      int
+	Size         int
+	Protocol     string
+	Interface    string
+	SrcAddress   netip.Addr
+	RoutingTable string
+}
+
+func (o TracerouteOptions) args(address string) []string {
+	args := []string{"/tool/traceroute", "=address=" + address}
+	if o.Count > 0 {
+		args = append(args, "=count="+strconv.Itoa(o.Count))
+	}
+	if o.MaxHops > 0 {
+		args = append(args, "=max-hops="+strconv.Itoa(o.MaxHops))
+	}
+	if o.Size > 0 {
+		args = append(args, "=size="+strconv.Itoa(o.Size))
+	}
+	if o.Protocol != "" {
+		args = append(args, "=protocol="+o.Protocol)
+	}
+	if o.Interface != "" {
+		args = append(args, "=interface="+o.Interface)
+	}
+	if o.SrcAddress.IsValid() {
+		args = append(args, "=src-address="+o.SrcAddress.String())
+	}
+	if o.RoutingTable != "" {
+		args = append(args, "=routing-table="+o.RoutingTable)
+	}
+	return args
+}
+
+// Hop is a line of the traceroute table.
+type Hop struct {
+	// Address is invalid for hops that did not answer.
+	Address netip.Addr
+	Loss    int
+	Sent    int
+	Last    time.Duration
+	Avg     time.Duration
+	Best    time.Duration
+	Worst   time.Duration
+	StdDev  time.Duration
+	Status  string
+}
+
+// TracerouteStream is a running /tool/traceroute. Every value is the whole hop list, refreshed after
+// each round of probes.
+type TracerouteStream struct {
+	*stream.Stream[[]Hop]
+}
+
+// Traceroute traces the route to address.
+func Traceroute(ctx context.Context, c *routeros.Client, address string, opts TracerouteOptions) (*TracerouteStream, error) {
+	s, err := stream.ListenSections(ctx, c, opts.args(address), c.Queue, parseHop)
+	if err != nil {
+		return nil, err
+	}
+	return &TracerouteStream{s}, nil
+}
+
+func parseHop(sen *proto.Sentence) (Hop, error) {
+	p := attrs.New(sen.Map)
+	h := Hop{
+		Address: p.Addr("address"),
+		Loss:    percent(p, "loss"),
+		Sent:    int(p.Int("sent")),
+		Last:    rtt(p, "last"),
+		Avg:     rtt(p, "avg"),
+		Best:    rtt(p, "best"),
+		Worst:   rtt(p, "worst"),
+		StdDev:  rtt(p, "std-dev"),
+		Status:  p.String("status"),
+	}
+	if err := p.Err(); err != nil {
+		return Hop{}, fmt.Errorf("traceroute hop %s: %w", sen.Map["address"], err)
+	}
+	return h, nil
+}
+
+// rtt parses round trip times, which traceroute reports as plain milliseconds (e.g. 1.3).
+func rtt(p *attrs.Parser, key string) time.Duration {
+	s := p.String(key)
+	if s == "" || s == "timeout" {
+		return 0
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(p.Float(key) * float64(time.Millisecond))
+	}
+	return p.Duration(key)
+}
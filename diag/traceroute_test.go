@@ -0,0 +1,48 @@
+package diag
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestTraceroute(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/tool/traceroute @l1 [{`address` `192.0.2.1`} {`count` `2`} {`max-hops` `3`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=0", "=address=10.0.0.1", "=loss=0%", "=sent=1", "=last=1.5", "=avg=1.5", "=best=1.5", "=worst=1.5", "=std-dev=0")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=0", "=loss=100%", "=sent=1", "=last=timeout")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=0", "=address=192.0.2.1", "=loss=0%", "=sent=1", "=last=12.1ms")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=1", "=address=10.0.0.1", "=loss=0%", "=sent=2", "=last=0.5", "=avg=1")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=1", "=loss=100%", "=sent=2", "=last=timeout")
+		s.WriteSentence(t, "!re", ".tag=l1", ".section=1", "=address=192.0.2.1", "=loss=50%", "=sent=2", "=last=timeout", "=status=timeout")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	tr, err := Traceroute(context.Background(), c, "192.0.2.1", TracerouteOptions{Count: 2, MaxHops: 3})
+	require.NoError(t, err)
+
+	var rounds [][]Hop
+	for hops := range tr.C() {
+		rounds = append(rounds, hops)
+	}
+	require.NoError(t, tr.Err())
+	require.Len(t, rounds, 2)
+
+	require.Equal(t, []Hop{
+		{Address: netip.MustParseAddr("10.0.0.1"), Sent: 1, Last: 1500 * time.Microsecond, Avg: 1500 * time.Microsecond, Best: 1500 * time.Microsecond, Worst: 1500 * time.Microsecond},
+		{Loss: 100, Sent: 1},
+		{Address: netip.MustParseAddr("192.0.2.1"), Sent: 1, Last: 12100 * time.Microsecond},
+	}, rounds[0])
+
+	require.Len(t, rounds[1], 3)
+	require.Equal(t, 50, rounds[1][2].Loss)
+	require.Equal(t, "timeout", rounds[1][2].Status)
+	require.Equal(t, time.Millisecond, rounds[1][0].Avg)
+}
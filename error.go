@@ -8,8 +8,9 @@ import (
 )
 
 var (
-	errAlreadyAsync   = errors.New("method Async() has already been called")
-	errAsyncLoopEnded = errors.New("method Async(): loop has ended - probably read error")
+	errAlreadyAsync = errors.New("method Async() has already been called")
+	// ErrAsyncLoopEnded is returned by the commands sent after the connection of an async client broke.
+	ErrAsyncLoopEnded = errors.New("method Async(): loop has ended - probably read error")
 )
 
 // UnknownReplyError records the sentence whose Word is unknown.
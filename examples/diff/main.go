@@ -0,0 +1,121 @@
+// Command diff compares the configuration of two RouterOS devices, or of saved snapshots.
+//
+//	diff -a 192.168.88.1:8728 -b snapshot.json -menus /interface,/ip/address
+//
+// Each side is read from a snapshot file if one exists at the given path, and from the
+// device at that address otherwise. The exit status is 1 when there are differences.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/config"
+)
+
+var (
+	debug    = flag.Bool("debug", false, "debug log level mode")
+	sideA    = flag.String("a", "", "RouterOS address and port, or snapshot file, to compare from")
+	sideB    = flag.String("b", "", "RouterOS address and port, or snapshot file, to compare to")
+	username = flag.String("username", "admin", "User name")
+	password = flag.String("password", "admin", "Password")
+	useTLS   = flag.Bool("tls", false, "Use TLS")
+	menus    = flag.String("menus", "/interface,/ip/address,/ip/route,/ip/firewall/filter,/ip/firewall/nat", "Comma separated menus to compare")
+	ignore   = flag.String("ignore", "", "Comma separated attributes to ignore")
+	dynamic  = flag.Bool("dynamic", false, "Compare dynamic items too")
+	save     = flag.String("save", "", "Save the snapshot of -b to this file")
+	asJSON   = flag.Bool("json", false, "Print the differences as JSON")
+)
+
+func dial(address string) (*routeros.Client, error) {
+	if *useTLS {
+		return routeros.DialTLS(address, *username, *password, nil)
+	}
+	return routeros.Dial(address, *username, *password)
+}
+
+func fatal(log *slog.Logger, message string, err error) {
+	log.Error(message, slog.Any("error", err))
+	os.Exit(2)
+}
+
+func snapshot(ctx context.Context, source string, paths []string) (*config.Snapshot, error) {
+	if f, err := os.Open(source); err == nil {
+		defer f.Close()
+		return config.ReadJSON(f)
+	}
+
+	c, err := dial(source)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	return config.Read(ctx, c, paths...)
+}
+
+func main() {
+	var err error
+	if err = flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		panic(err)
+	}
+
+	logLevel := slog.LevelInfo
+	if debug != nil && *debug {
+		logLevel = slog.LevelDebug
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     logLevel,
+	}))
+
+	ctx := context.Background()
+	paths := strings.Split(*menus, ",")
+
+	a, err := snapshot(ctx, *sideA, paths)
+	if err != nil {
+		fatal(log, "could not read "+*sideA, err)
+	}
+	b, err := snapshot(ctx, *sideB, paths)
+	if err != nil {
+		fatal(log, "could not read "+*sideB, err)
+	}
+
+	if *save != "" {
+		f, err := os.Create(*save)
+		if err != nil {
+			fatal(log, "could not save snapshot", err)
+		}
+		err = b.WriteJSON(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fatal(log, "could not save snapshot", err)
+		}
+	}
+
+	opts := config.DiffOptions{IncludeDynamic: *dynamic}
+	if *ignore != "" {
+		opts.Ignore = strings.Split(*ignore, ",")
+	}
+	r := config.Diff(a, b, opts)
+
+	if *asJSON {
+		err = r.WriteJSON(os.Stdout)
+	} else {
+		err = r.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fatal(log, "could not print differences", err)
+	}
+
+	if !r.Empty() {
+		os.Exit(1)
+	}
+}
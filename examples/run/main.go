@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log/slog"
 	"os"
 	"strings"
 
 	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/tlsconfig"
 )
 
 var (
@@ -17,9 +19,25 @@ var (
 	password = flag.String("password", "admin", "Password")
 	async    = flag.Bool("async", false, "Use async code")
 	useTLS   = flag.Bool("tls", false, "Use TLS")
+	pin      = flag.String("pin", "", "SHA-256 fingerprint of the certificate of the device, implies -tls")
+	anon     = flag.Bool("anon", false, "Use TLS with anonymous Diffie-Hellman, for api-ssl without certificate")
 )
 
 func dial() (*routeros.Client, error) {
+	if *anon {
+		return (&routeros.Dialer{
+			Address:     *address,
+			Credentials: routeros.StaticCredentials(*username, *password),
+			DialConn:    tlsconfig.DialAnonymousDH,
+		}).DialContext(context.Background())
+	}
+	if *pin != "" {
+		cfg, err := tlsconfig.Pinned(*pin)
+		if err != nil {
+			return nil, err
+		}
+		return routeros.DialTLS(*address, *username, *password, cfg)
+	}
 	if *useTLS {
 		return routeros.DialTLS(*address, *username, *password, nil)
 	}
@@ -0,0 +1,114 @@
+/*
+Package export parses the output of the RouterOS /export command into a tree of menus and
+commands, and renders such trees back into export syntax, so that configurations can be
+diffed and checked without a device.
+
+Both the default layout (a menu path line followed by its commands) and the terse one (the
+path repeated on every line) are understood. Menu paths are kept in API form, e.g.
+"/ip/firewall/filter" for the "/ip firewall filter" line of an export.
+*/
+package export
+
+import "strings"
+
+// Config is a parsed export.
+type Config struct {
+	// Comments are the comment lines before the first menu, without the leading "#".
+	Comments []string
+	// Menus are in the order they appear. A path may appear more than once.
+	Menus []*Menu
+}
+
+// Menu is a menu path followed by its commands.
+type Menu struct {
+	// Comments are the comment lines right before the path line.
+	Comments []string
+	// Path is in API form, e.g. "/ip/address".
+	Path     string
+	Commands []*Command
+}
+
+// Command is one command of an export, such as add or set.
+type Command struct {
+	// Comments are the comment lines right before the command.
+	Comments []string
+	Verb     string
+	// Find is the condition of a [ find ... ] selector, without "find" and "where",
+	// e.g. "default-name=ether1". It is empty when the command has no selector; a selector
+	// without condition ([ find ]) is kept in Args as an expression.
+	Find string
+	Args []Arg
+}
+
+// Arg is an argument of a command. Values are unquoted and unescaped.
+type Arg struct {
+	// Key is empty for positional arguments, e.g. telnet in "set telnet disabled=yes".
+	Key   string
+	Value string
+	// Expr tells that Value is a script expression in brackets, written as is.
+	Expr bool
+}
+
+// Commands returns the commands of every occurrence of the menu at path, in order.
+func (c *Config) Commands(path string) []*Command {
+	var cmds []*Command
+	for _, m := range c.Menus {
+		if m.Path == path {
+			cmds = append(cmds, m.Commands...)
+		}
+	}
+	return cmds
+}
+
+// Get returns the value of the argument key. If key is repeated, the last one wins.
+func (cmd *Command) Get(key string) (string, bool) {
+	for i := len(cmd.Args) - 1; i >= 0; i-- {
+		if cmd.Args[i].Key == key {
+			return cmd.Args[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Map returns the named arguments as a map.
+func (cmd *Command) Map() map[string]string {
+	m := make(map[string]string, len(cmd.Args))
+	for _, a := range cmd.Args {
+		if a.Key != "" {
+			m[a.Key] = a.Value
+		}
+	}
+	return m
+}
+
+// FindArgs returns the conditions of the selector when it is a plain list of key=value
+// terms, as written by /export. It returns nil for other conditions (and, or, ~, ...).
+func (cmd *Command) FindArgs() []Arg {
+	if cmd.Find == "" {
+		return nil
+	}
+	args, err := parseArgs(cmd.Find)
+	if err != nil {
+		return nil
+	}
+	for _, a := range args {
+		if a.Key == "" || a.Expr {
+			return nil
+		}
+	}
+	return args
+}
+
+// apiPath converts the words of an export path ("/ip", "firewall") into API form.
+func apiPath(words []string) string {
+	p := strings.Join(words, "/")
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// exportPath converts an API path into the form used by /export.
+func exportPath(path string) string {
+	return "/" + strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", " ")
+}
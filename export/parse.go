@@ -0,0 +1,349 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// verbs are the commands that end the path of a terse line.
+var verbs = map[string]bool{
+	"add":     true,
+	"set":     true,
+	"unset":   true,
+	"remove":  true,
+	"enable":  true,
+	"disable": true,
+	"move":    true,
+	"comment": true,
+	"reset":   true,
+}
+
+// ParseError reports a line of an export that could not be parsed.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("export: line %d: %s", err.Line, err.Msg)
+}
+
+// Parse reads an export from r.
+func Parse(r io.Reader) (*Config, error) {
+	p := &parser{c: &Config{}}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+
+	var (
+		logical strings.Builder
+		start   int
+		n       int
+	)
+	for sc.Scan() {
+		n++
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if logical.Len() == 0 {
+			start = n
+		} else {
+			// continuation lines are indented, the indentation is not part of the command
+			line = strings.TrimLeft(line, " \t")
+		}
+
+		if continued(line) {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		if err := p.line(start, logical.String()); err != nil {
+			return nil, err
+		}
+		logical.Reset()
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if logical.Len() > 0 {
+		if err := p.line(start, logical.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.c, nil
+}
+
+// ParseString parses an export held in s.
+func ParseString(s string) (*Config, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// continued reports whether line ends with a backslash that is not itself escaped.
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+type parser struct {
+	c        *Config
+	menu     *Menu
+	comments []string
+}
+
+func (p *parser) line(num int, line string) error {
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "":
+		return nil
+	case strings.HasPrefix(line, "#"):
+		p.comments = append(p.comments, line[1:])
+		return nil
+	case strings.HasPrefix(line, "/"):
+		path, rest := splitPath(line)
+		if rest == "" {
+			p.startMenu(path)
+			return nil
+		}
+		if p.menu == nil || p.menu.Path != path {
+			p.startMenu(path)
+		}
+		line = rest
+	}
+
+	if p.menu == nil {
+		return &ParseError{Line: num, Msg: "command outside of a menu"}
+	}
+
+	cmd, err := parseCommand(line)
+	if err != nil {
+		return &ParseError{Line: num, Msg: err.Error()}
+	}
+	cmd.Comments, p.comments = p.comments, nil
+	p.menu.Commands = append(p.menu.Commands, cmd)
+	return nil
+}
+
+func (p *parser) startMenu(path string) {
+	p.menu = &Menu{Path: path}
+	if len(p.c.Menus) == 0 {
+		p.c.Comments = p.comments
+	} else {
+		p.menu.Comments = p.comments
+	}
+	p.comments = nil
+	p.c.Menus = append(p.c.Menus, p.menu)
+}
+
+// splitPath splits a line starting with a menu path into the path, in API form, and the
+// command that follows it, if any.
+func splitPath(line string) (string, string) {
+	var words []string
+	rest := line
+	for rest != "" {
+		word, after, _ := strings.Cut(rest, " ")
+		if verbs[word] || strings.ContainsAny(word, `="[`) {
+			break
+		}
+		words = append(words, word)
+		rest = strings.TrimLeft(after, " \t")
+	}
+	return apiPath(words), rest
+}
+
+func parseCommand(line string) (*Command, error) {
+	verb, rest, _ := strings.Cut(line, " ")
+	cmd := &Command{Verb: verb}
+
+	rest = strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(rest, "[") {
+		expr, next, err := readBracket(rest, 0)
+		if err != nil {
+			return nil, err
+		}
+		if cond, ok := findCondition(expr); ok {
+			cmd.Find = cond
+			rest = rest[next:]
+		}
+	}
+
+	args, err := parseArgs(rest)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = args
+	return cmd, nil
+}
+
+// findCondition returns the condition of a "[ find ... ]" expression.
+func findCondition(expr string) (string, bool) {
+	s := strings.TrimSpace(expr[1 : len(expr)-1])
+	if s != "find" && !strings.HasPrefix(s, "find ") {
+		return "", false
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "find"))
+	if s == "where" || strings.HasPrefix(s, "where ") {
+		s = strings.TrimSpace(strings.TrimPrefix(s, "where"))
+	}
+	// a selector without condition stays an argument, so that it is not lost when rendering
+	return s, s != ""
+}
+
+func parseArgs(s string) ([]Arg, error) {
+	var args []Arg
+	for i := 0; ; {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i == len(s) {
+			return args, nil
+		}
+
+		var (
+			a   Arg
+			err error
+		)
+		switch s[i] {
+		case '[':
+			a.Expr = true
+			a.Value, i, err = readBracket(s, i)
+		case '"':
+			a.Value, i, err = readQuoted(s, i)
+		default:
+			j := i
+			for j < len(s) && !isSpace(s[j]) && s[j] != '=' {
+				j++
+			}
+			if j == len(s) || s[j] != '=' {
+				a.Value, i, err = readBare(s, i)
+				break
+			}
+
+			a.Key = s[i:j]
+			i = j + 1
+			switch {
+			case i == len(s) || isSpace(s[i]):
+			case s[i] == '"':
+				a.Value, i, err = readQuoted(s, i)
+			case s[i] == '[':
+				a.Expr = true
+				a.Value, i, err = readBracket(s, i)
+			default:
+				a.Value, i, err = readBare(s, i)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// readQuoted reads the quoted string starting at s[i] and returns its unescaped value and the
+// index following the closing quote.
+func readQuoted(s string, i int) (string, int, error) {
+	var sb strings.Builder
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			i = unescape(&sb, s, i)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// readBare reads an unquoted word starting at s[i].
+func readBare(s string, i int) (string, int, error) {
+	var sb strings.Builder
+	for ; i < len(s) && !isSpace(s[i]); i++ {
+		if s[i] == '\\' {
+			i = unescape(&sb, s, i)
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String(), i, nil
+}
+
+// readBracket reads the bracketed expression starting at s[i], brackets included.
+func readBracket(s string, i int) (string, int, error) {
+	start, depth := i, 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], i + 1, nil
+			}
+		case '"':
+			_, next, err := readQuoted(s, i)
+			if err != nil {
+				return "", 0, err
+			}
+			i = next - 1
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated expression")
+}
+
+// unescape writes the character escaped by the backslash at s[i] and returns the index of
+// the last byte of the escape sequence.
+func unescape(sb *strings.Builder, s string, i int) int {
+	if i+1 == len(s) {
+		sb.WriteByte('\\')
+		return i
+	}
+
+	// \XX is a byte in hexadecimal, with upper case digits
+	if i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+		sb.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+		return i + 2
+	}
+
+	c := s[i+1]
+	switch c {
+	case 'n':
+		c = '\n'
+	case 'r':
+		c = '\r'
+	case 't':
+		c = '\t'
+	case 'a':
+		c = '\a'
+	case 'b':
+		c = '\b'
+	case 'f':
+		c = '\f'
+	case 'v':
+		c = '\v'
+	case '_':
+		c = ' '
+	}
+	sb.WriteByte(c)
+	return i + 1
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'A' <= b && b <= 'F'
+}
+
+func unhex(b byte) byte {
+	if b <= '9' {
+		return b - '0'
+	}
+	return b - 'A' + 10
+}
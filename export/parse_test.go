@@ -0,0 +1,128 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `# 2024-01-02 12:00:00 by RouterOS 7.13
+# software id = ABCD-1234
+#
+/interface bridge
+add admin-mac=4C:5E:0C:12:34:56 auto-mac=no comment=defconf name=bridge
+/interface ethernet
+set [ find default-name=ether1 ] comment="WAN \"uplink\"" name=wan
+/ip firewall filter
+add action=accept chain=input comment="defconf: accept established,related,untracked" \
+    connection-state=established,related,untracked
+add action=drop chain=input comment=\
+    "defconf: drop all not coming from LAN" in-interface-list=!LAN
+/ip service
+set telnet disabled=yes
+# the script greets in Russian
+/system script
+add name=hello source=":log info \"\D0\9F\D1\80\D0\B8\D0\B2\D0\B5\D1\82\"\r\
+    \n:put \$x"
+/system identity
+set name=router
+`
+
+func TestParse(t *testing.T) {
+	c, err := ParseString(sample)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{" 2024-01-02 12:00:00 by RouterOS 7.13", " software id = ABCD-1234", ""}, c.Comments)
+	require.Len(t, c.Menus, 6)
+
+	require.Equal(t, "/interface/bridge", c.Menus[0].Path)
+	require.Equal(t, []Arg{
+		{Key: "admin-mac", Value: "4C:5E:0C:12:34:56"},
+		{Key: "auto-mac", Value: "no"},
+		{Key: "comment", Value: "defconf"},
+		{Key: "name", Value: "bridge"},
+	}, c.Menus[0].Commands[0].Args)
+
+	eth := c.Menus[1].Commands[0]
+	require.Equal(t, "set", eth.Verb)
+	require.Equal(t, "default-name=ether1", eth.Find)
+	require.Equal(t, []Arg{{Key: "default-name", Value: "ether1"}}, eth.FindArgs())
+	v, ok := eth.Get("comment")
+	require.True(t, ok)
+	require.Equal(t, `WAN "uplink"`, v)
+
+	filter := c.Commands("/ip/firewall/filter")
+	require.Len(t, filter, 2)
+	require.Equal(t, "established,related,untracked", filter[0].Map()["connection-state"])
+	require.Equal(t, "defconf: drop all not coming from LAN", filter[1].Map()["comment"])
+	require.Equal(t, "!LAN", filter[1].Map()["in-interface-list"])
+
+	require.Equal(t, []Arg{{Value: "telnet"}, {Key: "disabled", Value: "yes"}}, c.Menus[3].Commands[0].Args)
+
+	script := c.Menus[4]
+	require.Equal(t, []string{" the script greets in Russian"}, script.Comments)
+	require.Equal(t, ":log info \"Привет\"\r\n:put $x", script.Commands[0].Map()["source"])
+
+	require.Equal(t, "/system/identity", c.Menus[5].Path)
+	require.Equal(t, "router", c.Menus[5].Commands[0].Map()["name"])
+}
+
+func TestParseTerse(t *testing.T) {
+	c, err := ParseString(`/ip address add address=192.168.88.1/24 interface=bridge network=192.168.88.0
+/ip address add address=10.0.0.1/30 interface=wan
+/ip dns set allow-remote-requests=yes servers=1.1.1.1,8.8.8.8
+/interface list member add interface=wan list=WAN
+/ip firewall address-list add address=192.0.2.0/24 list=blocked
+/ip firewall nat add action=masquerade chain=srcnat out-interface-list=WAN
+`)
+	require.NoError(t, err)
+	require.Len(t, c.Menus, 5)
+
+	require.Equal(t, "/ip/address", c.Menus[0].Path)
+	require.Len(t, c.Menus[0].Commands, 2)
+	require.Equal(t, "/ip/dns", c.Menus[1].Path)
+	require.Equal(t, "/interface/list/member", c.Menus[2].Path)
+	require.Equal(t, "/ip/firewall/address-list", c.Menus[3].Path)
+	require.Equal(t, "masquerade", c.Menus[4].Commands[0].Map()["action"])
+}
+
+func TestParseFindWhere(t *testing.T) {
+	c, err := ParseString(`/interface wireless
+set [ find where name="wlan 1" ] ssid=home
+set [ find name~"wlan" and disabled ] disabled=no
+remove [ find ] 
+`)
+	require.NoError(t, err)
+
+	cmds := c.Menus[0].Commands
+	require.Equal(t, `name="wlan 1"`, cmds[0].Find)
+	require.Equal(t, []Arg{{Key: "name", Value: "wlan 1"}}, cmds[0].FindArgs())
+	require.Equal(t, `name~"wlan" and disabled`, cmds[1].Find)
+	require.Nil(t, cmds[1].FindArgs())
+	require.Equal(t, "", cmds[2].Find)
+	require.Equal(t, []Arg{{Value: "[ find ]", Expr: true}}, cmds[2].Args)
+}
+
+func TestParseExpression(t *testing.T) {
+	c, err := ParseString(`/ip route
+add gateway=[/ip dhcp-client get [find] gateway] comment=x
+`)
+	require.NoError(t, err)
+	require.Equal(t, Arg{Key: "gateway", Value: "[/ip dhcp-client get [find] gateway]", Expr: true}, c.Menus[0].Commands[0].Args[0])
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		line int
+	}{
+		{"add name=x\n", 1},
+		{"/ip address\nadd comment=\"open\n", 2},
+		{"/ip address\n\nset [ find name=x disabled=yes\n", 3},
+	} {
+		_, err := ParseString(tc.in)
+		var pe *ParseError
+		require.ErrorAs(t, err, &pe, tc.in)
+		require.Equal(t, tc.line, pe.Line, tc.in)
+	}
+}
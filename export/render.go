@@ -0,0 +1,116 @@
+package export
+
+import (
+	"io"
+	"strings"
+)
+
+// RenderOptions configure Render.
+type RenderOptions struct {
+	// Terse repeats the menu path on every command line, as /export terse does.
+	Terse bool
+}
+
+// Render writes c to w in export syntax. Long lines are not wrapped.
+func Render(w io.Writer, c *Config, opts RenderOptions) error {
+	var sb strings.Builder
+	writeComments(&sb, c.Comments)
+
+	for _, m := range c.Menus {
+		writeComments(&sb, m.Comments)
+
+		path := exportPath(m.Path)
+		if !opts.Terse {
+			sb.WriteString(path + "\n")
+		}
+		for _, cmd := range m.Commands {
+			writeComments(&sb, cmd.Comments)
+			if opts.Terse {
+				sb.WriteString(path + " ")
+			}
+			sb.WriteString(cmd.String() + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// String returns c in the default export layout.
+func (c *Config) String() string {
+	var sb strings.Builder
+	_ = Render(&sb, c, RenderOptions{})
+	return sb.String()
+}
+
+func writeComments(sb *strings.Builder, comments []string) {
+	for _, line := range comments {
+		sb.WriteString("#" + line + "\n")
+	}
+}
+
+// String returns the command in export syntax, without the menu path.
+func (cmd *Command) String() string {
+	var sb strings.Builder
+	sb.WriteString(cmd.Verb)
+	if cmd.Find != "" {
+		sb.WriteString(" [ find " + cmd.Find + " ]")
+	}
+	for _, a := range cmd.Args {
+		sb.WriteByte(' ')
+		if a.Key != "" {
+			sb.WriteString(a.Key + "=")
+		}
+		sb.WriteString(a.format())
+	}
+	return sb.String()
+}
+
+func (a Arg) format() string {
+	if a.Expr {
+		return a.Value
+	}
+	return Quote(a.Value)
+}
+
+// Quote returns v as written by /export: as is when it only has plain characters, and in
+// double quotes with special characters escaped otherwise. Bytes outside printable ASCII are
+// escaped in hexadecimal.
+func Quote(v string) string {
+	if v != "" && strings.IndexFunc(v, func(r rune) bool { return !isPlain(r) }) < 0 {
+		return v
+	}
+
+	const hex = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; c {
+		case '"', '\\', '$', '?':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if c < 0x20 || c >= 0x7F {
+				sb.WriteByte('\\')
+				sb.WriteByte(hex[c>>4])
+				sb.WriteByte(hex[c&0x0F])
+				continue
+			}
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func isPlain(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		strings.ContainsRune("-._:/,+*@!%&|~^<>", r)
+}
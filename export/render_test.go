@@ -0,0 +1,73 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderRoundTrip(t *testing.T) {
+	c, err := ParseString(sample)
+	require.NoError(t, err)
+
+	for _, terse := range []bool{false, true} {
+		var sb strings.Builder
+		require.NoError(t, Render(&sb, c, RenderOptions{Terse: terse}))
+
+		again, err := ParseString(sb.String())
+		require.NoError(t, err, sb.String())
+		require.Equal(t, c, again, sb.String())
+	}
+}
+
+func TestRender(t *testing.T) {
+	c := &Config{
+		Comments: []string{" by hand"},
+		Menus: []*Menu{{
+			Path: "/interface/ethernet",
+			Commands: []*Command{{
+				Verb: "set",
+				Find: "default-name=ether1",
+				Args: []Arg{{Key: "comment", Value: "to ISP"}, {Key: "name", Value: "wan"}},
+			}},
+		}, {
+			Path:     "/ip/service",
+			Comments: []string{" lock down"},
+			Commands: []*Command{{Verb: "set", Args: []Arg{{Value: "telnet"}, {Key: "disabled", Value: "yes"}}}},
+		}},
+	}
+
+	require.Equal(t, `# by hand
+/interface ethernet
+set [ find default-name=ether1 ] comment="to ISP" name=wan
+# lock down
+/ip service
+set telnet disabled=yes
+`, c.String())
+
+	var sb strings.Builder
+	require.NoError(t, Render(&sb, c, RenderOptions{Terse: true}))
+	require.Equal(t, `# by hand
+/interface ethernet set [ find default-name=ether1 ] comment="to ISP" name=wan
+# lock down
+/ip service set telnet disabled=yes
+`, sb.String())
+}
+
+func TestQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                  `""`,
+		"ether1":            "ether1",
+		"192.168.88.0/24":   "192.168.88.0/24",
+		"!LAN":              "!LAN",
+		"two words":         `"two words"`,
+		`say "hi"`:          `"say \"hi\""`,
+		"$var?":             `"\$var\?"`,
+		"a\\b":              `"a\\b"`,
+		"line\r\nnext\tend": `"line\r\nnext\tend"`,
+		"Привет":            `"\D0\9F\D1\80\D0\B8\D0\B2\D0\B5\D1\82"`,
+	} {
+		require.Equal(t, want, Quote(in), in)
+	}
+}
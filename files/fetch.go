@@ -0,0 +1,102 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// FetchOptions configure Fetch.
+type FetchOptions struct {
+	URL string
+	// DstPath is the file the download is saved to. Empty uses the name from the URL.
+	DstPath  string
+	User     string
+	Password string
+	// Upload sends SrcPath to URL instead of downloading it.
+	Upload  bool
+	SrcPath string
+	// CheckCertificate verifies the certificate of HTTPS servers.
+	CheckCertificate bool
+	Progress         Progress
+}
+
+func (o FetchOptions) args() []string {
+	args := []string{"/tool/fetch", "=url=" + o.URL}
+	if o.DstPath != "" {
+		args = append(args, "=dst-path="+o.DstPath)
+	}
+	if o.User != "" {
+		args = append(args, "=user="+o.User)
+	}
+	if o.Password != "" {
+		args = append(args, "=password="+o.Password)
+	}
+	if o.Upload {
+		args = append(args, "=upload=yes")
+	}
+	if o.SrcPath != "" {
+		args = append(args, "=src-path="+o.SrcPath)
+	}
+	if o.CheckCertificate {
+		args = append(args, "=check-certificate=yes")
+	}
+	return args
+}
+
+// FetchStatus is one progress report of /tool/fetch.
+type FetchStatus struct {
+	// Status is e.g. "connecting", "downloading", "finished" or "failed".
+	Status string
+	// Downloaded and Total are in bytes. The device reports them in KiB, so they are rounded.
+	Downloaded int64
+	Total      int64
+	Duration   time.Duration
+}
+
+// Fetch makes the device download (or upload) a file with /tool/fetch and waits until the transfer
+// ends. It returns the last status reported. If ctx is done first, /cancel is sent to the device.
+func Fetch(ctx context.Context, c *routeros.Client, opts FetchOptions) (FetchStatus, error) {
+	s, err := stream.Listen(ctx, c, opts.args(), c.Queue, parseFetchStatus)
+	if err != nil {
+		return FetchStatus{}, err
+	}
+
+	var last FetchStatus
+	for st := range s.C() {
+		last = st
+		if opts.Progress != nil && st.Status == "downloading" {
+			opts.Progress(st.Downloaded, st.Total)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return last, err
+	}
+
+	if last.Status == "failed" {
+		return last, fmt.Errorf("fetch %s: failed", opts.URL)
+	}
+	if opts.Progress != nil && last.Status == "finished" {
+		opts.Progress(last.Downloaded, last.Total)
+	}
+	return last, nil
+}
+
+func parseFetchStatus(sen *proto.Sentence) (FetchStatus, error) {
+	p := attrs.New(sen.Map)
+	st := FetchStatus{
+		Status:     p.String("status"),
+		Downloaded: p.Int("downloaded") << 10,
+		Total:      p.Int("total") << 10,
+		Duration:   p.Duration("duration"),
+	}
+	if err := p.Err(); err != nil {
+		return FetchStatus{}, fmt.Errorf("fetch status: %w", err)
+	}
+	return st, nil
+}
@@ -0,0 +1,48 @@
+package files
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestFetch(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/tool/fetch @l1 [{`url` `https://example.com/fw.npk`} {`dst-path` `fw.npk`} {`check-certificate` `yes`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=connecting")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=downloading", "=downloaded=512", "=total=1024", "=duration=1s")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=finished", "=downloaded=1024", "=total=1024", "=duration=2s")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	var progress [][2]int64
+	st, err := Fetch(context.Background(), c, FetchOptions{
+		URL:              "https://example.com/fw.npk",
+		DstPath:          "fw.npk",
+		CheckCertificate: true,
+		Progress:         func(done, total int64) { progress = append(progress, [2]int64{done, total}) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, FetchStatus{Status: "finished", Downloaded: 1 << 20, Total: 1 << 20, Duration: 2 * time.Second}, st)
+	require.Equal(t, [][2]int64{{512 << 10, 1 << 20}, {1 << 20, 1 << 20}}, progress)
+}
+
+func TestFetchFailure(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/tool/fetch @l1 [{`url` `http://192.0.2.1/a`} {`upload` `yes`} {`src-path` `a`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=status=connecting")
+		s.WriteSentence(t, "!trap", ".tag=l1", "=message=failure: connection timeout")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	_, err := Fetch(context.Background(), c, FetchOptions{URL: "http://192.0.2.1/a", Upload: true, SrcPath: "a"})
+	require.ErrorContains(t, err, "connection timeout")
+}
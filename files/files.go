@@ -0,0 +1,340 @@
+/*
+Package files transfers files to and from RouterOS devices through the API, using the /file
+menu and /tool/fetch, so no SCP or FTP access is needed.
+
+Uploads are sent in chunks: the first one is written with /file/add or /file/set, and the
+following ones are appended by a script run with /execute. As the script reads the whole file
+to append a chunk, devices take files of a few kilobytes (RouterOS 6) up to some megabytes
+(RouterOS 7); larger files are better fetched by the device itself with Fetch. Downloads are
+read in chunks with /file/read where the device supports it (RouterOS 7.13 and later), or in
+one go from the contents attribute otherwise.
+*/
+package files
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/export"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/scripts"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+var (
+	ErrNotFound = errors.New("file not found")
+	// ErrIntegrity is returned when a file read back from the device differs from what was sent.
+	ErrIntegrity = errors.New("file integrity check failed")
+)
+
+// DefaultChunkSize is the number of bytes read or written per command.
+const DefaultChunkSize = 32 << 10
+
+// maxPrealloc bounds the memory reserved from the size listed by the device, which may be wrong.
+const maxPrealloc = 1 << 20
+
+// proplist leaves out the contents, which would otherwise be sent for every small file.
+const proplist = "=.proplist=.id,name,type,size,creation-time,last-modified"
+
+// File is an entry of /file.
+type File struct {
+	ID   string
+	Name string
+	// Type is e.g. "directory", "script", ".txt file" or "backup".
+	Type string
+	Size int64
+	// SizeRounded tells that Size comes from a rounded value, as RouterOS 7 shows larger
+	// files, e.g. "1024.5 KiB", rather than an exact byte count.
+	SizeRounded bool
+	// CreationTime and LastModified are kept as formatted by the device, which differs between
+	// RouterOS versions.
+	CreationTime string
+	LastModified string
+}
+
+// IsDir reports whether the entry is a directory.
+func (f *File) IsDir() bool {
+	return f.Type == "directory"
+}
+
+// Progress is called as a transfer advances, with the number of bytes done so far and the
+// total, which is zero if unknown.
+type Progress func(done, total int64)
+
+// Client transfers files through a routeros.Client.
+type Client struct {
+	c *routeros.Client
+}
+
+// New returns a Client using c.
+func New(c *routeros.Client) *Client {
+	return &Client{c: c}
+}
+
+// ListFiles returns all the files and directories of the device.
+func (c *Client) ListFiles(ctx context.Context) ([]File, error) {
+	return c.list(ctx)
+}
+
+// Stat returns the file called name.
+func (c *Client) Stat(ctx context.Context, name string) (*File, error) {
+	list, err := c.list(ctx, "?name="+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return &list[0], nil
+}
+
+func (c *Client) list(ctx context.Context, query ...string) ([]File, error) {
+	r, err := c.c.RunArgsContext(ctx, append([]string{"/file/print", proplist}, query...))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(r.Re))
+	for _, sen := range r.Re {
+		f, err := parseFile(sen)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// RemoveFile removes the files called names.
+func (c *Client) RemoveFile(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := c.c.RunContext(ctx, "/file/remove", "=numbers="+strings.Join(names, ","))
+	return err
+}
+
+// Verify selects how UploadFile checks the file once written.
+type Verify int
+
+const (
+	// VerifySize compares the size of the file on the device. It is the default.
+	VerifySize Verify = iota
+	// VerifyContent downloads the file again and compares its checksum.
+	VerifyContent
+	// VerifyNone skips the check.
+	VerifyNone
+)
+
+// UploadOptions configure UploadFile.
+type UploadOptions struct {
+	Verify Verify
+	// ChunkSize is the number of bytes written per command. Zero means DefaultChunkSize.
+	ChunkSize int
+	// Progress is called after every chunk.
+	Progress Progress
+}
+
+// UploadFile writes data to the file called name, creating it if needed. The contents are sent
+// in chunks of opts.ChunkSize. VerifySize is skipped when the device only shows a rounded size.
+func (c *Client) UploadFile(ctx context.Context, name string, data []byte, opts UploadOptions) error {
+	total := int64(len(data))
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	first := data[:min(len(data), chunkSize)]
+	_, err := c.Stat(ctx, name)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		_, err = c.c.RunContext(ctx, "/file/add", "=name="+name, "=contents="+string(first))
+	case err == nil:
+		_, err = c.c.RunContext(ctx, "/file/set", "=numbers="+name, "=contents="+string(first))
+	}
+	if err != nil {
+		return err
+	}
+
+	for done := len(first); ; {
+		if opts.Progress != nil {
+			opts.Progress(int64(done), total)
+		}
+		if done == len(data) {
+			break
+		}
+
+		end := min(len(data), done+chunkSize)
+		if err := c.appendChunk(ctx, name, data[done:end]); err != nil {
+			return err
+		}
+		done = end
+	}
+
+	switch opts.Verify {
+	case VerifySize:
+		f, err := c.Stat(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !f.SizeRounded && f.Size != total {
+			return fmt.Errorf("%w: %s has %d bytes, want %d", ErrIntegrity, name, f.Size, total)
+		}
+	case VerifyContent:
+		got, err := c.DownloadFile(ctx, name, DownloadOptions{})
+		if err != nil {
+			return err
+		}
+		if sha256.Sum256(got) != sha256.Sum256(data) {
+			return fmt.Errorf("%w: %s differs from the uploaded data", ErrIntegrity, name)
+		}
+	}
+	return nil
+}
+
+// DownloadOptions configure DownloadFile.
+type DownloadOptions struct {
+	// ChunkSize is the number of bytes read per command. Zero means DefaultChunkSize.
+	ChunkSize int
+	Progress  Progress
+}
+
+// DownloadFile returns the contents of the file called name. The size of the result is checked
+// against the size listed by the device, unless that size is rounded.
+func (c *Client) DownloadFile(ctx context.Context, name string, opts DownloadOptions) ([]byte, error) {
+	f, err := c.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	// a rounded size does not tell where the file ends, so it is read until a chunk is empty
+	total := f.Size
+	if f.SizeRounded {
+		total = 0
+	}
+
+	data := make([]byte, 0, min(f.Size, maxPrealloc))
+	for f.SizeRounded || int64(len(data)) < f.Size {
+		chunk, err := c.readChunk(ctx, name, len(data), chunkSize)
+		if isNoSuchCommand(err) && len(data) == 0 {
+			data, err = c.readContents(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+
+		data = append(data, chunk...)
+		if opts.Progress != nil {
+			opts.Progress(int64(len(data)), total)
+		}
+	}
+
+	if !f.SizeRounded && int64(len(data)) != f.Size {
+		return nil, fmt.Errorf("%w: read %d bytes of %s, want %d", ErrIntegrity, len(data), name, f.Size)
+	}
+	return data, nil
+}
+
+// appendChunk appends chunk to the file called name with a script, as /file/set only replaces
+// the whole contents.
+func (c *Client) appendChunk(ctx context.Context, name string, chunk []byte) error {
+	code := fmt.Sprintf(":local f [/file find name=%s]; /file set $f contents=([/file get $f contents] . %s)",
+		export.Quote(name), export.Quote(string(chunk)))
+	_, err := scripts.New(c.c).Execute(ctx, code)
+	return err
+}
+
+// readChunk reads with /file/read, which answers with the raw bytes in the data attribute.
+func (c *Client) readChunk(ctx context.Context, name string, offset, size int) ([]byte, error) {
+	r, err := c.c.RunContext(ctx, "/file/read", "=file="+name,
+		"=offset="+strconv.Itoa(offset), "=chunk-size="+strconv.Itoa(size))
+	if err != nil {
+		return nil, err
+	}
+
+	// Done is nil if the client was closed while the command ran
+	if r.Done == nil {
+		return nil, fmt.Errorf("read %s: %w", name, io.ErrUnexpectedEOF)
+	}
+	for _, sen := range append(r.Re, r.Done) {
+		if b, ok := sen.Bytes("data"); ok {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+// readContents reads the whole file from the contents attribute, for devices without /file/read.
+func (c *Client) readContents(ctx context.Context, name string) ([]byte, error) {
+	r, err := c.c.RunContext(ctx, "/file/print", "=.proplist=contents", "?name="+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Re) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	b, _ := r.Re[0].Bytes("contents")
+	return b, nil
+}
+
+// isNoSuchCommand reports whether err tells that the device does not know the command.
+func isNoSuchCommand(err error) bool {
+	var devErr *routeros.DeviceError
+	return errors.As(err, &devErr) && strings.Contains(devErr.Sentence.Map["message"], "no such command")
+}
+
+func parseFile(sen *proto.Sentence) (File, error) {
+	p := attrs.New(sen.Map)
+	f := File{
+		ID:           sen.ID(),
+		Name:         p.String("name"),
+		Type:         p.String("type"),
+		CreationTime: p.String("creation-time"),
+		LastModified: p.String("last-modified"),
+	}
+	f.Size, f.SizeRounded = fileSize(p, "size")
+	if err := p.Err(); err != nil {
+		return File{}, fmt.Errorf("file %s: %w", f.Name, err)
+	}
+	return f, nil
+}
+
+// fileSize parses sizes, which RouterOS 6 sends as plain numbers and RouterOS 7 may send
+// with units and a space (e.g. "1024.5 KiB"). rounded is true for the latter.
+func fileSize(p *attrs.Parser, key string) (size int64, rounded bool) {
+	s := strings.ReplaceAll(p.String(key), " ", "")
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseInt(strings.TrimSuffix(s, "B"), 10, 64); err == nil {
+		return n, false
+	}
+	n, err := value.ParseSize(s)
+	if err != nil {
+		// let the parser record the error
+		return int64(p.Size(key)), false
+	}
+	return int64(n), true
+}
@@ -0,0 +1,249 @@
+package files
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+const printFiles = "/file/print @ [{`.proplist` `.id,name,type,size,creation-time,last-modified`}]"
+
+func TestListFiles(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles)
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=flash", "=type=directory", "=creation-time=jan/01/1970 00:00:01")
+		s.WriteSentence(t, "!re", "=.id=*2", "=name=flash/cert.pem", "=type=.pem file", "=size=1834")
+		s.WriteSentence(t, "!re", "=.id=*3", "=name=backup.backup", "=type=backup", "=size=12.5 KiB", "=last-modified=2024-01-02 15:04:05")
+		s.WriteSentence(t, "!done")
+	})
+
+	list, err := New(c).ListFiles(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{ID: "*1", Name: "flash", Type: "directory", CreationTime: "jan/01/1970 00:00:01"},
+		{ID: "*2", Name: "flash/cert.pem", Type: ".pem file", Size: 1834},
+		{ID: "*3", Name: "backup.backup", Type: "backup", Size: 12800, SizeRounded: true, LastModified: "2024-01-02 15:04:05"},
+	}, list)
+	require.True(t, list[0].IsDir())
+}
+
+func TestStatNotFound(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=missing.txt`]")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).Stat(context.Background(), "missing.txt")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRemoveFile(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/file/remove @ [{`numbers` `a.txt,b.txt`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	require.NoError(t, New(c).RemoveFile(context.Background(), "a.txt", "b.txt"))
+}
+
+func TestUploadFileNew(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=script.rsc`]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/add @ [{`name` `script.rsc`} {`contents` `:log info hello`}]")
+		s.WriteSentence(t, "!done", "=ret=*5")
+		s.ReadSentence(t, printFiles+" ?[`name=script.rsc`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=script.rsc", "=type=script", "=size=15")
+		s.WriteSentence(t, "!done")
+	})
+
+	var done, total int64
+	err := New(c).UploadFile(context.Background(), "script.rsc", []byte(":log info hello"), UploadOptions{
+		Progress: func(d, t int64) { done, total = d, t },
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(15), done)
+	require.Equal(t, int64(15), total)
+}
+
+func TestUploadFileChunked(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/add @ [{`name` `a.txt`} {`contents` `cost`}]")
+		s.WriteSentence(t, "!done", "=ret=*5")
+		s.ReadSentence(t, "/execute @ [{`script` `:local f [/file find name=a.txt]; "+
+			"/file set $f contents=([/file get $f contents] . \" \\$5\\n\")`} {`as-string` ``}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/execute @ [{`script` `:local f [/file find name=a.txt]; "+
+			"/file set $f contents=([/file get $f contents] . \"\\FF\")`} {`as-string` ``}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=a.txt", "=type=.txt file", "=size=9")
+		s.WriteSentence(t, "!done")
+	})
+
+	var progress []int64
+	err := New(c).UploadFile(context.Background(), "a.txt", []byte("cost $5\n\xff"), UploadOptions{
+		ChunkSize: 4,
+		Progress:  func(d, _ int64) { progress = append(progress, d) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 8, 9}, progress)
+}
+
+func TestUploadFileSizeMismatch(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=a.txt", "=type=.txt file", "=size=3")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/set @ [{`numbers` `a.txt`} {`contents` `hello`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=a.txt", "=type=.txt file", "=size=4")
+		s.WriteSentence(t, "!done")
+	})
+
+	err := New(c).UploadFile(context.Background(), "a.txt", []byte("hello"), UploadOptions{})
+	require.ErrorIs(t, err, ErrIntegrity)
+}
+
+func TestUploadFileVerifyContent(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=a.txt", "=type=.txt file", "=size=5")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/set @ [{`numbers` `a.txt`} {`contents` `hello`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*5", "=name=a.txt", "=type=.txt file", "=size=5")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `0`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!done", "=data=hellO")
+	})
+
+	err := New(c).UploadFile(context.Background(), "a.txt", []byte("hello"), UploadOptions{Verify: VerifyContent})
+	require.ErrorIs(t, err, ErrIntegrity)
+}
+
+func TestDownloadFileChunked(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=bin`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=bin", "=type=file", "=size=6")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `bin`} {`offset` `0`} {`chunk-size` `4`}]")
+		s.WriteSentence(t, "!re", "=data=\x00\x01\xff\xfe")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `bin`} {`offset` `4`} {`chunk-size` `4`}]")
+		s.WriteSentence(t, "!re", "=data=\r\n")
+		s.WriteSentence(t, "!done")
+	})
+
+	var progress []int64
+	data, err := New(c).DownloadFile(context.Background(), "bin", DownloadOptions{
+		ChunkSize: 4,
+		Progress:  func(done, _ int64) { progress = append(progress, done) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("\x00\x01\xff\xfe\r\n"), data)
+	require.Equal(t, []int64{4, 6}, progress)
+}
+
+func TestDownloadFileRoundedSize(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=bin`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=bin", "=type=file", "=size=0.0 KiB")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `bin`} {`offset` `0`} {`chunk-size` `4`}]")
+		s.WriteSentence(t, "!done", "=data=hell")
+		s.ReadSentence(t, "/file/read @ [{`file` `bin`} {`offset` `4`} {`chunk-size` `4`}]")
+		s.WriteSentence(t, "!done", "=data=o!")
+		s.ReadSentence(t, "/file/read @ [{`file` `bin`} {`offset` `6`} {`chunk-size` `4`}]")
+		s.WriteSentence(t, "!done", "=data=")
+	})
+
+	var totals []int64
+	data, err := New(c).DownloadFile(context.Background(), "bin", DownloadOptions{
+		ChunkSize: 4,
+		Progress:  func(_, total int64) { totals = append(totals, total) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello!"), data)
+	require.Equal(t, []int64{0, 0}, totals, "a rounded size is no total")
+}
+
+func TestDownloadFileWithoutRead(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=a.txt", "=type=.txt file", "=size=5")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `0`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!trap", "=message=no such command")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/print @ [{`.proplist` `contents`}] ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=contents=hello")
+		s.WriteSentence(t, "!done")
+	})
+
+	data, err := New(c).DownloadFile(context.Background(), "a.txt", DownloadOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+}
+
+func TestDownloadFileTruncated(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=a.txt", "=type=.txt file", "=size=10")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `0`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!done", "=data=hello")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `5`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!done", "=data=")
+	})
+
+	_, err := New(c).DownloadFile(context.Background(), "a.txt", DownloadOptions{})
+	require.ErrorIs(t, err, ErrIntegrity)
+}
+
+func TestDownloadFileBogusSize(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, printFiles+" ?[`name=a.txt`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=a.txt", "=type=.txt file", "=size=1099511627776")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `0`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!done", "=data=hello")
+		s.ReadSentence(t, "/file/read @ [{`file` `a.txt`} {`offset` `5`} {`chunk-size` `32768`}]")
+		s.WriteSentence(t, "!done", "=data=")
+	})
+
+	// the listed size is not reserved up front
+	_, err := New(c).DownloadFile(context.Background(), "a.txt", DownloadOptions{})
+	require.ErrorIs(t, err, ErrIntegrity)
+}
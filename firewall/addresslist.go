@@ -0,0 +1,300 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// DefaultWindow is the number of commands Sync keeps in flight when SyncOptions.Window is not set.
+const DefaultWindow = 64
+
+// AddressEntry is an entry of an address list.
+type AddressEntry struct {
+	ID   string
+	List string
+	// Address is kept as sent by the device, as it may also be a range or a DNS name.
+	Address string
+	Comment string
+	// Timeout is the time left before a dynamic entry is removed.
+	Timeout  time.Duration
+	Dynamic  bool
+	Disabled bool
+}
+
+// Prefix parses Address. It fails for ranges and DNS names.
+func (e AddressEntry) Prefix() (netip.Prefix, error) {
+	return value.ParsePrefix(e.Address)
+}
+
+// SyncOptions configure AddressLists.Sync.
+type SyncOptions struct {
+	// Timeout, if set, makes the added entries dynamic: the device removes them after Timeout.
+	// The time left of existing dynamic entries is set to Timeout again, while static ones are
+	// replaced by dynamic ones.
+	Timeout time.Duration
+	// Comment is set on the added entries.
+	Comment string
+	// Window is the maximum number of commands in flight. DefaultWindow is used if not set.
+	Window int
+	// Progress, if set, is called after every command with the work done so far.
+	// It is never called concurrently.
+	Progress func(SyncResult)
+}
+
+// SyncResult reports what Sync did.
+type SyncResult struct {
+	// Total is the number of commands Sync has to run. It grows if refreshed entries expire
+	// before the refresh, as they are added again.
+	Total int
+	// Added and Removed count the entries changed, Refreshed the ones whose timeout was set
+	// again, and Unchanged the ones already as wanted. Adds answered with "already have such
+	// entry" count as Unchanged, and replaced entries count as both removed and added.
+	Added, Removed, Refreshed, Unchanged int
+	// Failed counts the commands that returned an error.
+	Failed int
+}
+
+// AddressLists manages the address lists of the IPv4 or IPv6 firewall.
+type AddressLists struct {
+	c    *routeros.Client
+	path string
+}
+
+// NewAddressLists returns an AddressLists for /ip/firewall/address-list or /ipv6/firewall/address-list.
+func NewAddressLists(c *routeros.Client, family Family) *AddressLists {
+	return &AddressLists{c: c, path: "/" + string(family) + "/firewall/address-list"}
+}
+
+// Entries returns the entries of the list called name.
+func (a *AddressLists) Entries(ctx context.Context, name string) ([]AddressEntry, error) {
+	r, err := a.c.RunContext(ctx, a.path+"/print", "?list="+name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AddressEntry, 0, len(r.Re))
+	for _, sen := range r.Re {
+		e, err := parseAddressEntry(sen)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Add adds address to the list called name. A zero timeout adds a static entry.
+// Adding an address which is already in the list is not an error.
+func (a *AddressLists) Add(ctx context.Context, name, address string, timeout time.Duration, comment string) error {
+	_, err := a.c.RunArgsContext(ctx, a.addArgs(name, address, timeout, comment))
+	if isAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// Remove removes the entries with the given ids.
+func (a *AddressLists) Remove(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := a.c.RunContext(ctx, a.path+"/remove", "=numbers="+strings.Join(ids, ","))
+	return err
+}
+
+func (a *AddressLists) addArgs(name, address string, timeout time.Duration, comment string) []string {
+	args := []string{a.path + "/add", "=list=" + name, "=address=" + address}
+	if timeout > 0 {
+		args = append(args, "=timeout="+value.FormatDuration(timeout))
+	}
+	if comment != "" {
+		args = append(args, "=comment="+comment)
+	}
+	return args
+}
+
+// Sync makes the list called name contain exactly prefixes, with the timeout of opts: missing
+// prefixes are added, the timeout of the dynamic entries is refreshed in place, and the entries
+// not wanted are removed. A wanted entry is only removed and added again when it must change
+// between static and dynamic. All the commands are tried; the returned error joins the errors
+// of the failed ones.
+//
+// The commands are pipelined, the removes and refreshes before the adds. This needs async mode:
+// like Listen and RunBatch, Sync switches the client to it if needed, for good. The channel of
+// Async is not read then, but errors of the connection still fail the commands in flight.
+func (a *AddressLists) Sync(ctx context.Context, name string, prefixes []netip.Prefix, opts SyncOptions) (SyncResult, error) {
+	entries, err := a.Entries(ctx, name)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	want := make(map[netip.Prefix]bool, len(prefixes))
+	for _, p := range prefixes {
+		want[p.Masked()] = true
+	}
+
+	var res SyncResult
+	var removes, sets [][]string
+	// the prefix of every set, to add it again if the entry expired meanwhile
+	var refreshed []netip.Prefix
+
+	for _, e := range entries {
+		p, err := e.Prefix()
+		if err == nil && want[p.Masked()] {
+			// keep the first entry of every wanted prefix, duplicates are removed
+			switch {
+			case e.Timeout == opts.Timeout:
+				delete(want, p.Masked())
+				res.Unchanged++
+				continue
+			case opts.Timeout > 0 && e.Timeout > 0:
+				delete(want, p.Masked())
+				sets = append(sets, []string{a.path + "/set", "=numbers=" + e.ID, "=timeout=" + value.FormatDuration(opts.Timeout)})
+				refreshed = append(refreshed, p.Masked())
+				continue
+			}
+		}
+		removes = append(removes, []string{a.path + "/remove", "=numbers=" + e.ID})
+	}
+
+	adds := make([]netip.Prefix, 0, len(want))
+	for p := range want {
+		adds = append(adds, p)
+	}
+	sort.Slice(adds, func(i, j int) bool { return adds[i].Addr().Less(adds[j].Addr()) })
+
+	res.Total = len(removes) + len(sets) + len(adds)
+	if res.Total == 0 {
+		return res, nil
+	}
+
+	if !a.c.IsAsync() {
+		a.c.Async()
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	done := func(cmd []string, err error, ok func()) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case err == nil:
+			ok()
+		case cmd[0] == a.path+"/add" && isAlreadyExists(err), cmd[0] == a.path+"/remove" && isNoSuchItem(err):
+			res.Unchanged++
+		default:
+			res.Failed++
+			errs = append(errs, fmt.Errorf("%s: %w", strings.Join(cmd, " "), err))
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(res)
+		}
+	}
+
+	// an entry being replaced must be gone before it is added again
+	a.runAll(ctx, append(removes, sets...), opts.Window, func(i int, err error) {
+		if i < len(removes) {
+			done(removes[i], err, func() { res.Removed++ })
+			return
+		}
+
+		i -= len(removes)
+		refresh := func() { res.Refreshed++ }
+		if isNoSuchItem(err) {
+			// the entry expired before its refresh, so it is added again
+			err, refresh = nil, func() {
+				adds = append(adds, refreshed[i])
+				res.Total++
+			}
+		}
+		done(sets[i], err, refresh)
+	})
+
+	cmds := make([][]string, len(adds))
+	for i, p := range adds {
+		cmds[i] = a.addArgs(name, value.FormatPrefix(p), opts.Timeout, opts.Comment)
+	}
+	a.runAll(ctx, cmds, opts.Window, func(i int, err error) {
+		done(cmds[i], err, func() { res.Added++ })
+	})
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
+
+// runAll runs cmds with at most window of them in flight and calls done for each one.
+func (a *AddressLists) runAll(ctx context.Context, cmds [][]string, window int, done func(i int, err error)) {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	sem := make(chan struct{}, window)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i, cmd := range cmds {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		wg.Add(1)
+		go func(i int, cmd []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := a.c.RunArgsContext(ctx, cmd)
+			done(i, err)
+		}(i, cmd)
+	}
+}
+
+func parseAddressEntry(sen *proto.Sentence) (AddressEntry, error) {
+	p := attrs.New(sen.Map)
+	e := AddressEntry{
+		ID:       sen.ID(),
+		List:     p.String("list"),
+		Address:  p.String("address"),
+		Comment:  p.String("comment"),
+		Timeout:  p.Duration("timeout"),
+		Dynamic:  p.Bool("dynamic"),
+		Disabled: p.Bool("disabled"),
+	}
+	if err := p.Err(); err != nil {
+		return AddressEntry{}, fmt.Errorf("address list entry %s: %w", e.ID, err)
+	}
+	return e, nil
+}
+
+func deviceMessage(err error) string {
+	var devErr *routeros.DeviceError
+	if !errors.As(err, &devErr) {
+		return ""
+	}
+	return devErr.Sentence.Map["message"]
+}
+
+func isAlreadyExists(err error) bool {
+	return strings.Contains(deviceMessage(err), "already have such entry")
+}
+
+func isNoSuchItem(err error) bool {
+	return strings.Contains(deviceMessage(err), "no such item")
+}
@@ -0,0 +1,144 @@
+package firewall
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestAddressListEntries(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/address-list/print @ [] ?[`list=block`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=list=block", "=address=10.0.0.0/8", "=dynamic=false")
+		s.WriteSentence(t, "!re", "=.id=*2", "=list=block", "=address=1.2.3.4", "=timeout=1d23:59:58", "=dynamic=true")
+		s.WriteSentence(t, "!done")
+	})
+
+	entries, err := NewAddressLists(c, IPv4).Entries(context.Background(), "block")
+	require.NoError(t, err)
+	require.Equal(t, []AddressEntry{
+		{ID: "*1", List: "block", Address: "10.0.0.0/8"},
+		{ID: "*2", List: "block", Address: "1.2.3.4", Timeout: 2*24*time.Hour - 2*time.Second, Dynamic: true},
+	}, entries)
+}
+
+func TestAddressListAddExisting(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/address-list/add @ [{`list` `block`} {`address` `1.2.3.4`} {`timeout` `1h`}]")
+		s.WriteSentence(t, "!trap", "=message=failure: already have such entry")
+		s.WriteSentence(t, "!done")
+	})
+
+	require.NoError(t, NewAddressLists(c, IPv4).Add(context.Background(), "block", "1.2.3.4", time.Hour, ""))
+}
+
+func TestAddressListSync(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/address-list/print @ [] ?[`list=block`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=list=block", "=address=10.0.0.0/8", "=timeout=1d", "=dynamic=true")
+		s.WriteSentence(t, "!re", "=.id=*2", "=list=block", "=address=1.2.3.4")
+		s.WriteSentence(t, "!re", "=.id=*3", "=list=block", "=address=example.com")
+		s.WriteSentence(t, "!re", "=.id=*4", "=list=block", "=address=10.0.0.0/8")
+		s.WriteSentence(t, "!done")
+
+		// 3 removes and 3 adds, in any order
+		var got []string
+		for i := 0; i < 6; i++ {
+			sen := s.Next(t)
+			tag := sen.Tag
+			sen.Tag = ""
+			got = append(got, sen.String())
+
+			switch {
+			case sen.Map["address"] == "5.6.7.8":
+				s.WriteSentence(t, "!trap", "=message=failure: already have such entry", ".tag="+tag)
+			case sen.Map["numbers"] == "*4":
+				s.WriteSentence(t, "!trap", "=message=no such item", ".tag="+tag)
+			case sen.Map["address"] == "192.0.2.0/24":
+				s.WriteSentence(t, "!trap", "=message=invalid value", ".tag="+tag)
+			}
+			s.WriteSentence(t, "!done", ".tag="+tag)
+		}
+
+		require.ElementsMatch(t, []string{
+			"/ip/firewall/address-list/remove @ [{`numbers` `*2`}]",
+			"/ip/firewall/address-list/remove @ [{`numbers` `*3`}]",
+			"/ip/firewall/address-list/remove @ [{`numbers` `*4`}]",
+			"/ip/firewall/address-list/add @ [{`list` `block`} {`address` `5.6.7.8`} {`timeout` `1d`} {`comment` `feed`}]",
+			"/ip/firewall/address-list/add @ [{`list` `block`} {`address` `192.0.2.0/24`} {`timeout` `1d`} {`comment` `feed`}]",
+			"/ip/firewall/address-list/add @ [{`list` `block`} {`address` `2.2.2.2`} {`timeout` `1d`} {`comment` `feed`}]",
+		}, got)
+	})
+
+	var mu sync.Mutex
+	var calls int
+	res, err := NewAddressLists(c, IPv4).Sync(context.Background(), "block", []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("5.6.7.8/32"),
+		netip.MustParsePrefix("2.2.2.2/32"),
+		netip.MustParsePrefix("192.0.2.0/24"),
+	}, SyncOptions{
+		Timeout: 24 * time.Hour,
+		Comment: "feed",
+		Window:  3,
+		Progress: func(SyncResult) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		},
+	})
+
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "invalid value"), err.Error())
+	require.Equal(t, SyncResult{Total: 6, Added: 1, Removed: 2, Unchanged: 3, Failed: 1}, res)
+	require.Equal(t, 6, calls)
+}
+
+func TestAddressListSyncRefresh(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/address-list/print @ [] ?[`list=block`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=list=block", "=address=1.2.3.4", "=timeout=11:59:58", "=dynamic=true")
+		s.WriteSentence(t, "!re", "=.id=*2", "=list=block", "=address=5.6.7.8", "=timeout=1d", "=dynamic=true")
+		s.WriteSentence(t, "!re", "=.id=*3", "=list=block", "=address=9.9.9.9")
+		s.WriteSentence(t, "!re", "=.id=*4", "=list=block", "=address=2.2.2.2", "=timeout=1s", "=dynamic=true")
+		s.WriteSentence(t, "!done")
+
+		// static entries are replaced, dynamic ones are refreshed in place
+		s.ReadSentence(t, "/ip/firewall/address-list/remove @r1 [{`numbers` `*3`}]")
+		s.WriteSentence(t, "!done", ".tag=r1")
+		s.ReadSentence(t, "/ip/firewall/address-list/set @r2 [{`numbers` `*1`} {`timeout` `1d`}]")
+		s.WriteSentence(t, "!done", ".tag=r2")
+		s.ReadSentence(t, "/ip/firewall/address-list/set @r3 [{`numbers` `*4`} {`timeout` `1d`}]")
+		s.WriteSentence(t, "!trap", "=message=no such item", ".tag=r3")
+		s.WriteSentence(t, "!done", ".tag=r3")
+		s.ReadSentence(t, "/ip/firewall/address-list/add @r4 [{`list` `block`} {`address` `9.9.9.9`} {`timeout` `1d`}]")
+		s.WriteSentence(t, "!done", ".tag=r4")
+		// expired before its refresh
+		s.ReadSentence(t, "/ip/firewall/address-list/add @r5 [{`list` `block`} {`address` `2.2.2.2`} {`timeout` `1d`}]")
+		s.WriteSentence(t, "!done", ".tag=r5")
+	})
+
+	res, err := NewAddressLists(c, IPv4).Sync(context.Background(), "block", []netip.Prefix{
+		netip.MustParsePrefix("1.2.3.4/32"),
+		netip.MustParsePrefix("5.6.7.8/32"),
+		netip.MustParsePrefix("9.9.9.9/32"),
+		netip.MustParsePrefix("2.2.2.2/32"),
+	}, SyncOptions{Timeout: 24 * time.Hour, Window: 1})
+	require.NoError(t, err)
+	require.Equal(t, SyncResult{Total: 5, Added: 2, Removed: 1, Refreshed: 1, Unchanged: 1}, res)
+}
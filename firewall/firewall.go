@@ -0,0 +1,161 @@
+/*
+Package firewall is a typed client for the firewall tables of RouterOS devices:
+filter, nat, mangle and raw, for both IPv4 (/ip/firewall) and IPv6 (/ipv6/firewall).
+*/
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+var ErrNotFound = errors.New("rule not found")
+
+// Family selects the IPv4 or IPv6 firewall.
+type Family string
+
+const (
+	IPv4 Family = "ip"
+	IPv6 Family = "ipv6"
+)
+
+// Table is a firewall table.
+type Table string
+
+const (
+	Filter Table = "filter"
+	NAT    Table = "nat"
+	Mangle Table = "mangle"
+	Raw    Table = "raw"
+)
+
+// Client manages the rules of one firewall table through a routeros.Client.
+type Client struct {
+	c     *routeros.Client
+	table Table
+	path  string
+}
+
+// New returns a Client for table of family, e.g. New(c, IPv6, Filter) for /ipv6/firewall/filter.
+func New(c *routeros.Client, family Family, table Table) *Client {
+	return &Client{
+		c:     c,
+		table: table,
+		path:  "/" + string(family) + "/firewall/" + string(table),
+	}
+}
+
+// List returns all the rules of the table, in order.
+func (c *Client) List(ctx context.Context) ([]Rule, error) {
+	r, err := c.c.RunContext(ctx, c.path+"/print")
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(r.Re))
+	for _, sen := range r.Re {
+		rule, err := parseRule(sen)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Get returns the rule with the given id.
+func (c *Client) Get(ctx context.Context, id string) (*Rule, error) {
+	r, err := c.c.RunContext(ctx, c.path+"/print", "?.id="+id)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Re) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	rule, err := parseRule(r.Re[0])
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Add validates rule and appends it to the table. It returns the id of the new rule.
+func (c *Client) Add(ctx context.Context, rule Rule) (string, error) {
+	if err := rule.Validate(c.table); err != nil {
+		return "", err
+	}
+
+	r, err := c.c.RunArgsContext(ctx, append([]string{c.path + "/add"}, rule.args()...))
+	if err != nil {
+		return "", err
+	}
+
+	// Done is nil if the client was closed while the command ran
+	if r.Done == nil {
+		return "", fmt.Errorf("add rule: %w", io.ErrUnexpectedEOF)
+	}
+	return r.Done.Map["ret"], nil
+}
+
+// Update validates rule and sets its attributes on the rule with the same ID.
+// Empty fields are left as they are on the device, but Disabled and Log are always set.
+func (c *Client) Update(ctx context.Context, rule Rule) error {
+	if rule.ID == "" {
+		return fmt.Errorf("%w: rule without ID", ErrNotFound)
+	}
+	if err := rule.Validate(c.table); err != nil {
+		return err
+	}
+
+	args := append([]string{c.path + "/set", "=.id=" + rule.ID}, rule.args()...)
+	if !rule.Log {
+		args = append(args, "=log=no")
+	}
+	if !rule.Disabled {
+		args = append(args, "=disabled=no")
+	}
+
+	_, err := c.c.RunArgsContext(ctx, args)
+	return err
+}
+
+// Remove removes the rules with the given ids.
+func (c *Client) Remove(ctx context.Context, ids ...string) error {
+	return c.run(ctx, "remove", ids)
+}
+
+// Enable enables the rules with the given ids.
+func (c *Client) Enable(ctx context.Context, ids ...string) error {
+	return c.run(ctx, "enable", ids)
+}
+
+// Disable disables the rules with the given ids.
+func (c *Client) Disable(ctx context.Context, ids ...string) error {
+	return c.run(ctx, "disable", ids)
+}
+
+// Move moves the rule id in front of the rule before. An empty before moves it to the end.
+func (c *Client) Move(ctx context.Context, id, before string) error {
+	args := []string{c.path + "/move", "=numbers=" + id}
+	if before != "" {
+		args = append(args, "=destination="+before)
+	}
+
+	_, err := c.c.RunArgsContext(ctx, args)
+	return err
+}
+
+func (c *Client) run(ctx context.Context, command string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := c.c.RunContext(ctx, c.path+"/"+command, "=numbers="+strings.Join(ids, ","))
+	return err
+}
@@ -0,0 +1,135 @@
+package firewall
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestList(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ipv6/firewall/filter/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*1", "=chain=input", "=action=accept", "=connection-state=established,related",
+			"=bytes=1000", "=packets=10", "=dynamic=false", "=invalid=false", "=disabled=false", "=log=false")
+		s.WriteSentence(t, "!re", "=.id=*2", "=chain=input", "=action=drop", "=in-interface-list=WAN",
+			"=hop-limit=equal:1", "=disabled=true", "=log=true", "=log-prefix=drop")
+		s.WriteSentence(t, "!done")
+	})
+
+	rules, err := New(c, IPv6, Filter).List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	require.Equal(t, Rule{
+		ID:              "*1",
+		Chain:           "input",
+		Action:          "accept",
+		ConnectionState: "established,related",
+		Bytes:           1000,
+		Packets:         10,
+	}, rules[0])
+
+	require.Equal(t, Rule{
+		ID:              "*2",
+		Chain:           "input",
+		Action:          "drop",
+		InInterfaceList: "WAN",
+		Disabled:        true,
+		Log:             true,
+		LogPrefix:       "drop",
+		Extra:           map[string]string{"hop-limit": "equal:1"},
+	}, rules[1])
+}
+
+func TestAdd(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/nat/add @ [{`chain` `dstnat`} {`protocol` `tcp`} {`dst-port` `8080`} "+
+			"{`in-interface-list` `WAN`} {`action` `dst-nat`} {`to-addresses` `192.168.88.10`} {`to-ports` `80`} {`comment` `web`}]")
+		s.WriteSentence(t, "!done", "=ret=*A")
+	})
+
+	id, err := New(c, IPv4, NAT).Add(context.Background(), Rule{
+		Chain:           "dstnat",
+		Action:          "dst-nat",
+		Protocol:        "tcp",
+		DstPort:         "8080",
+		InInterfaceList: "WAN",
+		ToAddresses:     "192.168.88.10",
+		ToPorts:         "80",
+		Comment:         "web",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "*A", id)
+}
+
+func TestAddClosed(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	c.Async()
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/filter/add @r1 [{`chain` `input`} {`action` `accept`}]")
+		// let the write of the command return before closing
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, c.Close())
+	})
+
+	_, err := New(c, IPv4, Filter).Add(context.Background(), Rule{Chain: "input", Action: "accept"})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestAddInvalid(t *testing.T) {
+	c, _ := routerostest.NewPair(t)
+
+	_, err := New(c, IPv4, Filter).Add(context.Background(), Rule{Chain: "srcnat", Action: "accept"})
+	require.ErrorIs(t, err, ErrInvalidChain)
+}
+
+func TestUpdateMoveEnableDisableRemove(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/filter/set @ [{`.id` `*3`} {`chain` `forward`} {`action` `drop`} {`log` `no`} {`disabled` `no`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/move @ [{`numbers` `*3`} {`destination` `*1`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/move @ [{`numbers` `*3`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/disable @ [{`numbers` `*3,*4`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/enable @ [{`numbers` `*3`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/remove @ [{`numbers` `*4`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	ctx := context.Background()
+	fw := New(c, IPv4, Filter)
+	require.NoError(t, fw.Update(ctx, Rule{ID: "*3", Chain: "forward", Action: "drop"}))
+	require.NoError(t, fw.Move(ctx, "*3", "*1"))
+	require.NoError(t, fw.Move(ctx, "*3", ""))
+	require.NoError(t, fw.Disable(ctx, "*3", "*4"))
+	require.NoError(t, fw.Enable(ctx, "*3"))
+	require.NoError(t, fw.Remove(ctx, "*4"))
+	require.NoError(t, fw.Remove(ctx), "nothing to remove")
+}
+
+func TestGetNotFound(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/raw/print @ [] ?[`.id=*9`]")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c, IPv4, Raw).Get(context.Background(), "*9")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,227 @@
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+var (
+	ErrInvalidChain     = errors.New("invalid chain")
+	ErrInvalidAction    = errors.New("invalid action")
+	ErrMissingArgument  = errors.New("missing argument")
+	ErrPortWithoutProto = errors.New("ports need a protocol with ports (tcp, udp, udp-lite, sctp, dccp)")
+)
+
+// Rule is a firewall rule. String fields take the values as RouterOS does, including negation
+// (!192.168.0.0/16), ranges (1000-2000) and lists (established,related).
+type Rule struct {
+	// ID is set on rules read from the device.
+	ID string
+
+	Chain    string
+	Action   string
+	Comment  string
+	Disabled bool
+
+	// matchers
+	SrcAddress         string
+	DstAddress         string
+	SrcAddressList     string
+	DstAddressList     string
+	Protocol           string
+	SrcPort            string
+	DstPort            string
+	InInterface        string
+	OutInterface       string
+	InInterfaceList    string
+	OutInterfaceList   string
+	ConnectionState    string
+	ConnectionNATState string
+	ConnectionMark     string
+	PacketMark         string
+	RoutingMark        string
+
+	// action arguments
+	JumpTarget         string
+	ToAddresses        string
+	ToPorts            string
+	NewConnectionMark  string
+	NewPacketMark      string
+	NewRoutingMark     string
+	Passthrough        *bool
+	AddressList        string
+	AddressListTimeout string
+	RejectWith         string
+	Log                bool
+	LogPrefix          string
+
+	// Extra has the attributes not covered by the fields above, sent and compared as they are.
+	Extra map[string]string
+
+	// read-only state
+	Dynamic bool
+	Invalid bool
+	Bytes   uint64
+	Packets uint64
+}
+
+// stringFields maps the string fields of r to their attribute names, matchers first.
+func (r *Rule) stringFields() []struct {
+	key     string
+	v       *string
+	matcher bool
+} {
+	return []struct {
+		key     string
+		v       *string
+		matcher bool
+	}{
+		{"chain", &r.Chain, true},
+		{"src-address", &r.SrcAddress, true},
+		{"dst-address", &r.DstAddress, true},
+		{"src-address-list", &r.SrcAddressList, true},
+		{"dst-address-list", &r.DstAddressList, true},
+		{"protocol", &r.Protocol, true},
+		{"src-port", &r.SrcPort, true},
+		{"dst-port", &r.DstPort, true},
+		{"in-interface", &r.InInterface, true},
+		{"out-interface", &r.OutInterface, true},
+		{"in-interface-list", &r.InInterfaceList, true},
+		{"out-interface-list", &r.OutInterfaceList, true},
+		{"connection-state", &r.ConnectionState, true},
+		{"connection-nat-state", &r.ConnectionNATState, true},
+		{"connection-mark", &r.ConnectionMark, true},
+		{"packet-mark", &r.PacketMark, true},
+		{"routing-mark", &r.RoutingMark, true},
+		{"action", &r.Action, false},
+		{"jump-target", &r.JumpTarget, false},
+		{"to-addresses", &r.ToAddresses, false},
+		{"to-ports", &r.ToPorts, false},
+		{"new-connection-mark", &r.NewConnectionMark, false},
+		{"new-packet-mark", &r.NewPacketMark, false},
+		{"new-routing-mark", &r.NewRoutingMark, false},
+		{"address-list", &r.AddressList, false},
+		{"address-list-timeout", &r.AddressListTimeout, false},
+		{"reject-with", &r.RejectWith, false},
+		{"log-prefix", &r.LogPrefix, false},
+		{"comment", &r.Comment, false},
+	}
+}
+
+// readOnly are attributes reported by print that cannot be set.
+var readOnly = []string{"bytes", "packets", "dynamic", "invalid"}
+
+// args returns the attribute words describing r, in a stable order.
+func (r Rule) args() []string {
+	var words []string
+	for _, f := range r.stringFields() {
+		if *f.v != "" {
+			words = append(words, "="+f.key+"="+*f.v)
+		}
+	}
+	if r.Passthrough != nil {
+		words = append(words, "=passthrough="+value.FormatBool(*r.Passthrough))
+	}
+	if r.Log {
+		words = append(words, "=log=yes")
+	}
+	if r.Disabled {
+		words = append(words, "=disabled=yes")
+	}
+
+	keys := make([]string, 0, len(r.Extra))
+	for k := range r.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		words = append(words, "="+k+"="+r.Extra[k])
+	}
+	return words
+}
+
+// matchers returns the attributes selecting the packets r applies to.
+func (r Rule) matchers() map[string]string {
+	m := make(map[string]string)
+	for _, f := range r.stringFields() {
+		if f.matcher && *f.v != "" {
+			m[f.key] = *f.v
+		}
+	}
+	for k, v := range r.Extra {
+		m[k] = v
+	}
+	return m
+}
+
+// Equal reports whether r and o configure the same rule. IDs, counters and other
+// read-only state are ignored.
+func (r Rule) Equal(o Rule) bool {
+	return slices.Equal(r.args(), o.args())
+}
+
+// SameMatchers reports whether r and o match the same packets, whatever they do with them.
+// Attributes in Extra are treated as matchers.
+func (r Rule) SameMatchers(o Rule) bool {
+	a, b := r.matchers(), o.matchers()
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// Index returns the index of the first rule in rules equal to r, or -1.
+func Index(rules []Rule, r Rule) int {
+	return slices.IndexFunc(rules, r.Equal)
+}
+
+func parseRule(sen *proto.Sentence) (Rule, error) {
+	p := attrs.New(sen.Map)
+
+	r := Rule{
+		ID:       sen.ID(),
+		Disabled: p.Bool("disabled"),
+		Log:      p.Bool("log"),
+		Dynamic:  p.Bool("dynamic"),
+		Invalid:  p.Bool("invalid"),
+		Bytes:    p.Uint("bytes"),
+		Packets:  p.Uint("packets"),
+	}
+	if p.Has("passthrough") {
+		pt := p.Bool("passthrough")
+		r.Passthrough = &pt
+	}
+
+	known := append([]string{"disabled", "log", "passthrough"}, readOnly...)
+	for _, f := range r.stringFields() {
+		*f.v = p.String(f.key)
+		known = append(known, f.key)
+	}
+
+	for _, pair := range sen.List {
+		if strings.HasPrefix(pair.Key, ".") || slices.Contains(known, pair.Key) {
+			continue
+		}
+		if r.Extra == nil {
+			r.Extra = make(map[string]string)
+		}
+		r.Extra[pair.Key] = pair.Value
+	}
+
+	if err := p.Err(); err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+	return r, nil
+}
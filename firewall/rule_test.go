@@ -0,0 +1,36 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleEqual(t *testing.T) {
+	yes := true
+	a := Rule{ID: "*1", Chain: "forward", Action: "mark-connection", NewConnectionMark: "voip", Passthrough: &yes, Bytes: 10}
+	b := Rule{ID: "*7", Chain: "forward", Action: "mark-connection", NewConnectionMark: "voip", Passthrough: &yes}
+
+	require.True(t, a.Equal(b), "ids and counters are ignored")
+
+	b.Comment = "voip"
+	require.False(t, a.Equal(b))
+	require.True(t, a.SameMatchers(b), "comments are not matchers")
+
+	b.Protocol = "udp"
+	require.False(t, a.SameMatchers(b))
+
+	require.Equal(t, 1, Index([]Rule{{Chain: "input"}, b, a}, b))
+	require.Equal(t, -1, Index([]Rule{{Chain: "input"}}, b))
+}
+
+func TestRuleExtraIsMatcher(t *testing.T) {
+	a := Rule{Chain: "input", Extra: map[string]string{"ttl": "equal:1"}}
+	b := Rule{Chain: "input", Extra: map[string]string{"ttl": "equal:2"}}
+
+	require.False(t, a.SameMatchers(b))
+	require.False(t, a.Equal(b))
+
+	b.Extra["ttl"] = "equal:1"
+	require.True(t, a.Equal(b))
+}
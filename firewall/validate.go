@@ -0,0 +1,101 @@
+package firewall
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var builtinChains = map[Table][]string{
+	Filter: {"input", "forward", "output"},
+	NAT:    {"srcnat", "dstnat"},
+	Mangle: {"prerouting", "input", "forward", "output", "postrouting"},
+	Raw:    {"prerouting", "output"},
+}
+
+var commonActions = []string{"accept", "add-dst-to-address-list", "add-src-to-address-list", "jump", "log", "passthrough", "return"}
+
+var tableActions = map[Table][]string{
+	Filter: {"drop", "fasttrack-connection", "reject", "tarpit"},
+	NAT:    {"dst-nat", "endpoint-independent-nat", "masquerade", "netmap", "redirect", "same", "src-nat"},
+	Mangle: {
+		"change-dscp", "change-mss", "change-ttl", "clear-df", "fasttrack-connection", "mark-connection",
+		"mark-packet", "mark-routing", "route", "set-priority", "sniff-pc", "sniff-tzsp", "strip-ipv4-options",
+	},
+	Raw: {"drop", "notrack"},
+}
+
+// protocols whose rules may match ports
+var portProtocols = []string{"tcp", "udp", "udp-lite", "sctp", "dccp", "6", "17", "132", "33", "136"}
+
+// Validate checks that r can be added to table: the chain is valid for the table, the action is
+// known and has its required arguments, and ports are only matched together with a protocol.
+func (r Rule) Validate(table Table) error {
+	builtin, ok := builtinChains[table]
+	if !ok {
+		return fmt.Errorf("unknown firewall table %q", table)
+	}
+
+	switch {
+	case r.Chain == "" || strings.ContainsAny(r.Chain, " \t\n"):
+		return fmt.Errorf("%w: %q", ErrInvalidChain, r.Chain)
+	case !slices.Contains(builtin, r.Chain) && isBuiltinChain(r.Chain):
+		// custom chains are fine, but not the built-in chains of other tables
+		return fmt.Errorf("%w: %s is not a chain of the %s table", ErrInvalidChain, r.Chain, table)
+	}
+
+	// accept is the default action
+	action := r.Action
+	if action == "" {
+		action = "accept"
+	}
+	if !slices.Contains(commonActions, action) && !slices.Contains(tableActions[table], action) {
+		return fmt.Errorf("%w: %s is not an action of the %s table", ErrInvalidAction, action, table)
+	}
+
+	var missing string
+	switch action {
+	case "jump":
+		if r.JumpTarget == "" {
+			missing = "jump-target"
+		}
+	case "add-dst-to-address-list", "add-src-to-address-list":
+		if r.AddressList == "" {
+			missing = "address-list"
+		}
+	case "mark-connection":
+		if r.NewConnectionMark == "" {
+			missing = "new-connection-mark"
+		}
+	case "mark-packet":
+		if r.NewPacketMark == "" {
+			missing = "new-packet-mark"
+		}
+	case "mark-routing":
+		if r.NewRoutingMark == "" {
+			missing = "new-routing-mark"
+		}
+	case "dst-nat", "netmap", "same":
+		if r.ToAddresses == "" && r.ToPorts == "" {
+			missing = "to-addresses or to-ports"
+		}
+	}
+	if missing != "" {
+		return fmt.Errorf("%w: action %s needs %s", ErrMissingArgument, action, missing)
+	}
+
+	if (r.SrcPort != "" || r.DstPort != "") && !slices.Contains(portProtocols, strings.TrimPrefix(r.Protocol, "!")) {
+		return ErrPortWithoutProto
+	}
+
+	return nil
+}
+
+func isBuiltinChain(chain string) bool {
+	for _, chains := range builtinChains {
+		if slices.Contains(chains, chain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	for _, d := range []struct {
+		name  string
+		table Table
+		rule  Rule
+		err   error
+	}{
+		{"default action", Filter, Rule{Chain: "input"}, nil},
+		{"custom chain", Filter, Rule{Chain: "from-wan", Action: "drop"}, nil},
+		{"no chain", Filter, Rule{Action: "drop"}, ErrInvalidChain},
+		{"chain of other table", Filter, Rule{Chain: "prerouting", Action: "drop"}, ErrInvalidChain},
+		{"nat chain", NAT, Rule{Chain: "srcnat", Action: "masquerade"}, nil},
+		{"action of other table", NAT, Rule{Chain: "srcnat", Action: "drop"}, ErrInvalidAction},
+		{"unknown action", Raw, Rule{Chain: "prerouting", Action: "explode"}, ErrInvalidAction},
+		{"jump", Filter, Rule{Chain: "input", Action: "jump"}, ErrMissingArgument},
+		{"jump target", Filter, Rule{Chain: "input", Action: "jump", JumpTarget: "from-wan"}, nil},
+		{"address list", Raw, Rule{Chain: "prerouting", Action: "add-src-to-address-list"}, ErrMissingArgument},
+		{"mark routing", Mangle, Rule{Chain: "prerouting", Action: "mark-routing"}, ErrMissingArgument},
+		{"dst-nat", NAT, Rule{Chain: "dstnat", Action: "dst-nat"}, ErrMissingArgument},
+		{"port", Filter, Rule{Chain: "input", DstPort: "22"}, ErrPortWithoutProto},
+		{"port with protocol", Filter, Rule{Chain: "input", Protocol: "tcp", DstPort: "22"}, nil},
+	} {
+		t.Run(d.name, func(t *testing.T) {
+			err := d.rule.Validate(d.table)
+			if d.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, d.err)
+		})
+	}
+
+	require.Error(t, Rule{Chain: "input"}.Validate("bridge"))
+}
@@ -0,0 +1,229 @@
+/*
+Package interfaces is a typed client for the /interface menu of RouterOS devices.
+*/
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/internal/stream"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+var ErrNotFound = errors.New("interface not found")
+
+// Interface is an entry of /interface.
+type Interface struct {
+	ID          string
+	Name        string
+	DefaultName string
+	Type        string
+	Comment     string
+	MTU         int64
+	ActualMTU   int64
+	L2MTU       int64
+	MACAddress  net.HardwareAddr
+
+	Running  bool
+	Disabled bool
+	Dynamic  bool
+	Slave    bool
+
+	RxByte   uint64
+	TxByte   uint64
+	RxPacket uint64
+	TxPacket uint64
+	RxDrop   uint64
+	TxDrop   uint64
+	RxError  uint64
+	TxError  uint64
+
+	LinkDowns      uint64
+	LastLinkUpTime string
+}
+
+// Filter selects interfaces returned by List. Zero fields match everything.
+type Filter struct {
+	Name string
+	Type string
+
+	Running  *bool
+	Disabled *bool
+}
+
+func (f Filter) words() []string {
+	var words []string
+	if f.Name != "" {
+		words = append(words, "?name="+f.Name)
+	}
+	if f.Type != "" {
+		words = append(words, "?type="+f.Type)
+	}
+	if f.Running != nil {
+		words = append(words, "?running="+strconv.FormatBool(*f.Running))
+	}
+	if f.Disabled != nil {
+		words = append(words, "?disabled="+strconv.FormatBool(*f.Disabled))
+	}
+	return words
+}
+
+// Client runs /interface commands through a routeros.Client.
+type Client struct {
+	c *routeros.Client
+}
+
+// New returns a Client using c.
+func New(c *routeros.Client) *Client {
+	return &Client{c: c}
+}
+
+// List returns the interfaces matching f.
+func (c *Client) List(ctx context.Context, f Filter) ([]Interface, error) {
+	r, err := c.c.RunArgsContext(ctx, append([]string{"/interface/print"}, f.words()...))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Interface, 0, len(r.Re))
+	for _, sen := range r.Re {
+		i, err := parseInterface(sen)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, nil
+}
+
+// Get returns the interface called name.
+func (c *Client) Get(ctx context.Context, name string) (*Interface, error) {
+	list, err := c.List(ctx, Filter{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return &list[0], nil
+}
+
+// Enable enables the interface called name.
+func (c *Client) Enable(ctx context.Context, name string) error {
+	_, err := c.c.RunContext(ctx, "/interface/enable", "=numbers="+name)
+	return err
+}
+
+// Disable disables the interface called name.
+func (c *Client) Disable(ctx context.Context, name string) error {
+	_, err := c.c.RunContext(ctx, "/interface/disable", "=numbers="+name)
+	return err
+}
+
+// SetComment replaces the comment of the interface called name.
+func (c *Client) SetComment(ctx context.Context, name, comment string) error {
+	_, err := c.c.RunContext(ctx, "/interface/set", "=numbers="+name, "=comment="+comment)
+	return err
+}
+
+func parseInterface(sen *proto.Sentence) (Interface, error) {
+	p := attrs.New(sen.Map)
+	i := Interface{
+		ID:          sen.ID(),
+		Name:        p.String("name"),
+		DefaultName: p.String("default-name"),
+		Type:        p.String("type"),
+		Comment:     p.String("comment"),
+		MTU:         mtu(p, "mtu"),
+		ActualMTU:   mtu(p, "actual-mtu"),
+		L2MTU:       mtu(p, "l2mtu"),
+		MACAddress:  p.MAC("mac-address"),
+
+		Running:  p.Bool("running"),
+		Disabled: p.Bool("disabled"),
+		Dynamic:  p.Bool("dynamic"),
+		Slave:    p.Bool("slave"),
+
+		RxByte:   p.Uint("rx-byte"),
+		TxByte:   p.Uint("tx-byte"),
+		RxPacket: p.Uint("rx-packet"),
+		TxPacket: p.Uint("tx-packet"),
+		RxDrop:   p.Uint("rx-drop"),
+		TxDrop:   p.Uint("tx-drop"),
+		RxError:  p.Uint("rx-error"),
+		TxError:  p.Uint("tx-error"),
+
+		LinkDowns:      p.Uint("link-downs"),
+		LastLinkUpTime: p.String("last-link-up-time"),
+	}
+	if err := p.Err(); err != nil {
+		return Interface{}, fmt.Errorf("interface %s: %w", i.Name, err)
+	}
+	return i, nil
+}
+
+// mtu parses an MTU, which is "auto" on some interface types.
+func mtu(p *attrs.Parser, key string) int64 {
+	if p.String(key) == "auto" {
+		return 0
+	}
+	return p.Int(key)
+}
+
+// TrafficSample is one reply of /interface/monitor-traffic.
+type TrafficSample struct {
+	Name string
+
+	RxBitsPerSecond    uint64
+	TxBitsPerSecond    uint64
+	RxPacketsPerSecond uint64
+	TxPacketsPerSecond uint64
+	RxDropsPerSecond   uint64
+	TxDropsPerSecond   uint64
+	RxErrorsPerSecond  uint64
+	TxErrorsPerSecond  uint64
+}
+
+// TrafficMonitor is a running /interface/monitor-traffic.
+type TrafficMonitor struct {
+	*stream.Stream[TrafficSample]
+}
+
+// MonitorTraffic starts monitoring the traffic of the named interfaces. The device sends one sample
+// per interface every second until ctx is done or Cancel is called.
+func (c *Client) MonitorTraffic(ctx context.Context, names ...string) (*TrafficMonitor, error) {
+	sentence := []string{"/interface/monitor-traffic", "=interface=" + value.FormatList(names)}
+
+	s, err := stream.Listen(ctx, c.c, sentence, max(c.c.Queue, len(names)), parseTrafficSample)
+	if err != nil {
+		return nil, err
+	}
+	return &TrafficMonitor{s}, nil
+}
+
+func parseTrafficSample(sen *proto.Sentence) (TrafficSample, error) {
+	p := attrs.New(sen.Map)
+	s := TrafficSample{
+		Name: p.String("name"),
+
+		RxBitsPerSecond:    p.Rate("rx-bits-per-second"),
+		TxBitsPerSecond:    p.Rate("tx-bits-per-second"),
+		RxPacketsPerSecond: p.Uint("rx-packets-per-second"),
+		TxPacketsPerSecond: p.Uint("tx-packets-per-second"),
+		RxDropsPerSecond:   p.Uint("rx-drops-per-second"),
+		TxDropsPerSecond:   p.Uint("tx-drops-per-second"),
+		RxErrorsPerSecond:  p.Uint("rx-errors-per-second"),
+		TxErrorsPerSecond:  p.Uint("tx-errors-per-second"),
+	}
+	if err := p.Err(); err != nil {
+		return TrafficSample{}, fmt.Errorf("traffic of %s: %w", s.Name, err)
+	}
+	return s, nil
+}
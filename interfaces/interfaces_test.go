@@ -0,0 +1,122 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestList(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/print @ [] ?[`type=ether` `running=true`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=ether1", "=type=ether", "=mtu=1500", "=actual-mtu=1500",
+			"=mac-address=4C:5E:0C:12:34:56", "=running=true", "=disabled=false", "=rx-byte=1234", "=tx-byte=5678")
+		s.WriteSentence(t, "!re", "=.id=*2", "=name=ether2", "=type=ether", "=mtu=auto", "=running=true", "=comment=uplink")
+		s.WriteSentence(t, "!done")
+	})
+
+	running := true
+	list, err := New(c).List(context.Background(), Filter{Type: "ether", Running: &running})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	require.Equal(t, "*1", list[0].ID)
+	require.Equal(t, "ether1", list[0].Name)
+	require.Equal(t, int64(1500), list[0].MTU)
+	require.Equal(t, "4c:5e:0c:12:34:56", list[0].MACAddress.String())
+	require.True(t, list[0].Running)
+	require.Equal(t, uint64(1234), list[0].RxByte)
+	require.Equal(t, uint64(5678), list[0].TxByte)
+
+	require.Equal(t, int64(0), list[1].MTU)
+	require.Equal(t, "uplink", list[1].Comment)
+}
+
+func TestListInvalidValue(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/print @ []")
+		s.WriteSentence(t, "!re", "=name=ether1", "=rx-byte=lots")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).List(context.Background(), Filter{})
+	require.ErrorContains(t, err, "rx-byte")
+}
+
+func TestGetNotFound(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/print @ [] ?[`name=ether9`]")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).Get(context.Background(), "ether9")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestEnableDisableComment(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/enable @ [{`numbers` `ether1`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/interface/disable @ [{`numbers` `ether1`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/interface/set @ [{`numbers` `ether1`} {`comment` `to core`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	ic := New(c)
+	require.NoError(t, ic.Enable(context.Background(), "ether1"))
+	require.NoError(t, ic.Disable(context.Background(), "ether1"))
+	require.NoError(t, ic.SetComment(context.Background(), "ether1", "to core"))
+}
+
+func TestMonitorTraffic(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/monitor-traffic @l1 [{`interface` `ether1,ether2`}]")
+		s.WriteSentence(t, "!re", ".tag=l1", "=name=ether1", "=rx-bits-per-second=10.5M", "=tx-bits-per-second=128000",
+			"=rx-packets-per-second=900", "=tx-packets-per-second=100")
+		s.WriteSentence(t, "!re", ".tag=l1", "=name=ether2", "=rx-bits-per-second=0", "=tx-bits-per-second=0")
+		s.ReadSentence(t, "/cancel @r2 [{`tag` `l1`}]")
+		s.WriteSentence(t, "!trap", "=category=2", "=message=interrupted", ".tag=l1")
+		s.WriteSentence(t, "!done", ".tag=r2")
+		s.WriteSentence(t, "!done", ".tag=l1")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := New(c).MonitorTraffic(ctx, "ether1", "ether2")
+	require.NoError(t, err)
+
+	sample := <-m.C()
+	require.Equal(t, TrafficSample{
+		Name:               "ether1",
+		RxBitsPerSecond:    10500000,
+		TxBitsPerSecond:    128000,
+		RxPacketsPerSecond: 900,
+		TxPacketsPerSecond: 100,
+	}, sample)
+
+	sample = <-m.C()
+	require.Equal(t, "ether2", sample.Name)
+
+	m.Cancel()
+
+	_, ok := <-m.C()
+	require.False(t, ok, "channel should be closed after Cancel")
+	require.ErrorIs(t, m.Err(), context.Canceled)
+}
@@ -0,0 +1,181 @@
+// Package attrs converts attribute values of RouterOS sentences into Go types.
+package attrs
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// Parser reads typed values from a sentence map. Missing attributes give zero values;
+// the first malformed one is recorded and reported by Err.
+type Parser struct {
+	m   map[string]string
+	err error
+}
+
+// New returns a Parser over the attributes in m.
+func New(m map[string]string) *Parser {
+	return &Parser{m: m}
+}
+
+// Err returns the first error that occurred while parsing.
+func (p *Parser) Err() error {
+	return p.err
+}
+
+func (p *Parser) fail(key string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("attribute %s: %w", key, err)
+	}
+}
+
+// Has reports whether the attribute key is present.
+func (p *Parser) Has(key string) bool {
+	_, ok := p.m[key]
+	return ok
+}
+
+// String returns the value of key as is.
+func (p *Parser) String(key string) string {
+	return p.m[key]
+}
+
+// Bool parses a yes/no or true/false value.
+func (p *Parser) Bool(key string) bool {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return false
+	}
+	b, err := value.ParseBool(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return b
+}
+
+// Int parses a signed integer.
+func (p *Parser) Int(key string) int64 {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return n
+}
+
+// Uint parses an unsigned integer.
+func (p *Parser) Uint(key string) uint64 {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return n
+}
+
+// Float parses a floating point number.
+func (p *Parser) Float(key string) float64 {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return f
+}
+
+// Rate parses a rate in bits per second.
+func (p *Parser) Rate(key string) uint64 {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	n, err := value.ParseRate(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return n
+}
+
+// Size parses a size in bytes.
+func (p *Parser) Size(key string) uint64 {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	n, err := value.ParseSize(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return n
+}
+
+// Duration parses a RouterOS duration.
+func (p *Parser) Duration(key string) time.Duration {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return 0
+	}
+	d, err := value.ParseDuration(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return d
+}
+
+// List splits a comma separated list.
+func (p *Parser) List(key string) []string {
+	return value.ParseList(p.m[key])
+}
+
+// Addr parses an IP address.
+func (p *Parser) Addr(key string) netip.Addr {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return netip.Addr{}
+	}
+	a, err := value.ParseAddr(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return a
+}
+
+// Prefix parses an IP prefix or a single address.
+func (p *Parser) Prefix(key string) netip.Prefix {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return netip.Prefix{}
+	}
+	pr, err := value.ParsePrefix(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return pr
+}
+
+// MAC parses a MAC address.
+func (p *Parser) MAC(key string) net.HardwareAddr {
+	s, ok := p.m[key]
+	if !ok || s == "" {
+		return nil
+	}
+	mac, err := value.ParseMAC(s)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return mac
+}
@@ -0,0 +1,99 @@
+// Package routerostest provides a fake RouterOS device for testing packages built on routeros.Client.
+package routerostest
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/wire"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+type conn struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (c *conn) Close() error {
+	if err := c.PipeReader.Close(); err != nil {
+		return err
+	}
+
+	return c.PipeWriter.Close()
+}
+
+// Server is the device side of a pair created by NewPair.
+type Server struct {
+	r *wire.Reader
+	w proto.Writer
+	io.Closer
+}
+
+// NewPair returns a client connected to a fake device. Both are closed when the test ends.
+func NewPair(t *testing.T) (*routeros.Client, *Server) {
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c, err := routeros.NewClient(&conn{ar, bw})
+	require.NoError(t, err)
+
+	s := &Server{
+		r:      wire.NewReader(br),
+		w:      proto.NewWriter(aw),
+		Closer: &conn{br, aw},
+	}
+
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = s.Close()
+	})
+
+	return c, s
+}
+
+// Serve runs the device side of a test in a goroutine. The test does not end before script returns.
+func (s *Server) Serve(t *testing.T, script func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		script()
+	}()
+
+	t.Cleanup(func() {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("fake device script did not finish")
+		}
+	})
+}
+
+// Next reads the next command sent by the client.
+func (s *Server) Next(t *testing.T) *wire.Command {
+	sen, err := s.r.ReadCommand()
+	require.NoError(t, err)
+	t.Logf("< %s\n", sen)
+	return sen
+}
+
+// ReadSentence reads the next command and checks that it matches want, in the format of
+// wire.Command.String: that of proto.Sentence.String, without redacted secrets.
+func (s *Server) ReadSentence(t *testing.T, want string) {
+	sen := s.Next(t)
+	require.Equal(t, want, sen.String(), "wrong sentence")
+}
+
+// WriteSentence sends a reply sentence to the client.
+func (s *Server) WriteSentence(t *testing.T, sentence ...string) {
+	t.Logf("> %#q\n", sentence)
+	s.w.BeginSentence()
+	for _, word := range sentence {
+		s.w.WriteWord(word)
+	}
+
+	require.NoError(t, s.w.EndSentence())
+}
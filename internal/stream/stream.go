@@ -0,0 +1,247 @@
+// Package stream turns the !re sentences of a listen command into a channel of typed values.
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// decoder turns sentences into values. flush returns what is left once the command is done.
+type decoder[T any] interface {
+	decode(sen *proto.Sentence) ([]T, error)
+	flush() []T
+}
+
+// Stream delivers the values parsed from a running listen command.
+type Stream[T any] struct {
+	c      chan T
+	l      *routeros.ListenReply
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Listen sends sentence and parses every !re reply with parse. When ctx is done or Cancel is called,
+// /cancel is sent to the device so the command stops there too.
+func Listen[T any](ctx context.Context, c *routeros.Client, sentence []string, queueSize int, parse func(*proto.Sentence) (T, error)) (*Stream[T], error) {
+	return start[T](ctx, c, sentence, queueSize, nil, single[T](parse))
+}
+
+// ListenAfter is like Listen, but holds the replies back until ready is closed, so that parse
+// may depend on what is read once the command runs. The replies keep being read meanwhile, so
+// the other commands of the client are not blocked.
+func ListenAfter[T any](ctx context.Context, c *routeros.Client, sentence []string, queueSize int, ready <-chan struct{}, parse func(*proto.Sentence) (T, error)) (*Stream[T], error) {
+	return start[T](ctx, c, sentence, queueSize, ready, single[T](parse))
+}
+
+// ListenSections is like Listen, but collects the replies sharing the same .section into one value.
+// Commands such as /tool/torch and /tool/traceroute resend their whole table as a new section.
+func ListenSections[T any](ctx context.Context, c *routeros.Client, sentence []string, queueSize int, parse func(*proto.Sentence) (T, error)) (*Stream[[]T], error) {
+	return start[[]T](ctx, c, sentence, queueSize, nil, &sections[T]{parse: parse})
+}
+
+func start[T any](ctx context.Context, c *routeros.Client, sentence []string, queueSize int, ready <-chan struct{}, dec decoder[T]) (*Stream[T], error) {
+	// canceling the context of a listen cancels the reads of the whole client, so ctx only
+	// stops the stream, which sends /cancel for its own command
+	l, err := c.ListenArgsQueueContext(context.WithoutCancel(ctx), sentence, queueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &Stream[T]{
+		c:      make(chan T, queueSize),
+		l:      l,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go s.run(ctx, ready, dec)
+
+	return s, nil
+}
+
+// C returns the channel of parsed values. It is closed when the command ends.
+func (s *Stream[T]) C() <-chan T {
+	return s.c
+}
+
+// Err returns the error that ended the stream, if any. It must be called after C is closed.
+func (s *Stream[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Cancel stops the command and waits for the stream to end.
+func (s *Stream[T]) Cancel() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Stream[T]) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Stream[T]) run(ctx context.Context, ready <-chan struct{}, dec decoder[T]) {
+	defer close(s.done)
+	defer close(s.c)
+	defer s.cancel()
+
+	if ready != nil {
+		held, ok := s.wait(ctx, ready)
+		if !ok {
+			return
+		}
+		for _, sen := range held {
+			values, err := dec.decode(sen)
+			if err != nil {
+				s.stop(err)
+				return
+			}
+			if !s.send(ctx, values) {
+				s.stop(ctx.Err())
+				return
+			}
+		}
+	}
+
+	reC := s.l.Chan()
+	for {
+		var values []T
+
+		select {
+		case <-ctx.Done():
+			s.stop(ctx.Err())
+			return
+		case sen, ok := <-reC:
+			if !ok {
+				if err := s.l.Err(); err != nil {
+					s.setErr(err)
+					return
+				}
+				s.send(ctx, dec.flush())
+				return
+			}
+
+			var err error
+			if values, err = dec.decode(sen); err != nil {
+				s.stop(err)
+				return
+			}
+		}
+
+		if !s.send(ctx, values) {
+			s.stop(ctx.Err())
+			return
+		}
+	}
+}
+
+// wait holds the replies back until ready is closed and returns them. It returns false if ctx
+// is done first.
+func (s *Stream[T]) wait(ctx context.Context, ready <-chan struct{}) ([]*proto.Sentence, bool) {
+	var held []*proto.Sentence
+
+	reC := s.l.Chan()
+	for {
+		select {
+		case <-ready:
+			return held, true
+		case <-ctx.Done():
+			s.stop(ctx.Err())
+			return nil, false
+		case sen, ok := <-reC:
+			if !ok {
+				// the command ended, the main loop sees it once ready is closed
+				reC = nil
+				continue
+			}
+			held = append(held, sen)
+		}
+	}
+}
+
+// send delivers values unless ctx is done first.
+func (s *Stream[T]) send(ctx context.Context, values []T) bool {
+	for _, v := range values {
+		select {
+		case s.c <- v:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// stop sends /cancel and drains the replies until the device confirms the command ended.
+func (s *Stream[T]) stop(err error) {
+	s.setErr(err)
+
+	go func() {
+		if _, err := s.l.Cancel(); err != nil {
+			s.setErr(err)
+		}
+	}()
+
+	for range s.l.Chan() {
+	}
+}
+
+// single parses every sentence into one value.
+type single[T any] func(*proto.Sentence) (T, error)
+
+func (f single[T]) decode(sen *proto.Sentence) ([]T, error) {
+	v, err := f(sen)
+	if err != nil {
+		return nil, err
+	}
+	return []T{v}, nil
+}
+
+func (f single[T]) flush() []T {
+	return nil
+}
+
+// sections collects consecutive sentences with the same .section.
+type sections[T any] struct {
+	parse   func(*proto.Sentence) (T, error)
+	section string
+	pending []T
+}
+
+func (d *sections[T]) decode(sen *proto.Sentence) ([][]T, error) {
+	v, err := d.parse(sen)
+	if err != nil {
+		return nil, err
+	}
+
+	var out [][]T
+	if sec := sen.Section(); sec != d.section {
+		out = d.flush()
+		d.section = sec
+	}
+	d.pending = append(d.pending, v)
+	return out, nil
+}
+
+func (d *sections[T]) flush() [][]T {
+	if len(d.pending) == 0 {
+		return nil
+	}
+	out := [][]T{d.pending}
+	d.pending = nil
+	return out
+}
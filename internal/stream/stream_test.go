@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+func parseName(sen *proto.Sentence) (string, error) {
+	return sen.Map["name"], nil
+}
+
+// collect runs a listen that the device ends after one reply, and returns the values.
+func collect(t *testing.T, c *routeros.Client, s *routerostest.Server, tag int) []string {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ReadSentence(t, fmt.Sprintf("/test @l%d []", tag))
+		s.WriteSentence(t, "!re", fmt.Sprintf(".tag=l%d", tag), "=name=a")
+		s.WriteSentence(t, "!done", fmt.Sprintf(".tag=l%d", tag))
+	}()
+
+	st, err := Listen(context.Background(), c, []string{"/test"}, 1, parseName)
+	require.NoError(t, err)
+
+	var values []string
+	for v := range st.C() {
+		values = append(values, v)
+	}
+	require.NoError(t, st.Err())
+	<-done
+	return values
+}
+
+func TestListen(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	require.Equal(t, []string{"a"}, collect(t, c, s, 1))
+}
+
+func TestListenNoGoroutineLeak(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	// the first stream starts the async loop of the client
+	collect(t, c, s, 1)
+	before := runtime.NumGoroutine()
+
+	for i := 2; i <= 11; i++ {
+		collect(t, c, s, i)
+	}
+
+	// polled by hand, as require.Eventually runs its condition in a goroutine of its own
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutines left behind by finished streams")
+}
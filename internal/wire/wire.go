@@ -0,0 +1,119 @@
+// Package wire reads commands the way a device does, for the fake devices of tests. Unlike
+// proto.Reader, which reads the replies of a device, it accepts query words, and the commands
+// it reads format without redacting secrets, so that tests check what was actually sent.
+package wire
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// Command is a command sent to a device.
+type Command struct {
+	*proto.Sentence
+	// Query has the query words (?type=ether, ?#|, ...) without the leading question mark.
+	Query []string
+}
+
+// String formats the command as proto.Sentence.String does, with the query words last and
+// without redacting secrets, e.g. "/interface/print @r1 [{`.proplist` `name`}] ?[`type=ether`]".
+func (c *Command) String() string {
+	s := fmt.Sprintf("%s @%s %#q", c.Word, c.Tag, c.List)
+	if len(c.Attrs) > 0 {
+		s += fmt.Sprintf(" %#q", c.Attrs)
+	}
+	if len(c.Query) > 0 {
+		s += fmt.Sprintf(" ?%#q", c.Query)
+	}
+	return s
+}
+
+// Reader reads commands.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadCommand reads the next command.
+func (r *Reader) ReadCommand() (*Command, error) {
+	c := &Command{Sentence: proto.NewSentence()}
+	for {
+		word, err := r.readWord()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case word == "":
+			return c, nil
+		case c.Word == "":
+			c.Word = word
+		case strings.HasPrefix(word, "="):
+			key, value, _ := strings.Cut(word[1:], "=")
+			c.List = append(c.List, proto.Pair{Key: key, Value: value})
+			c.Map[key] = value
+			c.Raw = append(c.Raw, []byte(value))
+		case strings.HasPrefix(word, ".tag="):
+			c.Tag = word[len(".tag="):]
+		case strings.HasPrefix(word, "."):
+			key, value, _ := strings.Cut(word, "=")
+			c.Attrs = append(c.Attrs, proto.Pair{Key: key, Value: value})
+		case strings.HasPrefix(word, "?"):
+			c.Query = append(c.Query, word[1:])
+		default:
+			return nil, fmt.Errorf("%w: %#q", proto.ErrInvalidWord, word)
+		}
+	}
+}
+
+func (r *Reader) readWord() (string, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	// the number of bytes following the first one of the length
+	var more int
+	l := int(b)
+	switch {
+	case b&0x80 == 0x00:
+	case b&0xC0 == 0x80:
+		more, l = 1, l&^0xC0
+	case b&0xE0 == 0xC0:
+		more, l = 2, l&^0xE0
+	case b&0xF0 == 0xE0:
+		more, l = 3, l&^0xF0
+	case b == 0xF0:
+		more, l = 4, 0
+	default:
+		return "", fmt.Errorf("%w: 0x%02X", proto.ErrReservedControlByte, b)
+	}
+	for i := 0; i < more; i++ {
+		if b, err = r.r.ReadByte(); err != nil {
+			return "", unexpectedEOF(err)
+		}
+		l = l<<8 | int(b)
+	}
+
+	word := make([]byte, l)
+	if _, err := io.ReadFull(r.r, word); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return string(word), nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package wire
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+func TestReadCommand(t *testing.T) {
+	long := strings.Repeat("x", 300)
+
+	buf := &bytes.Buffer{}
+	w := proto.NewWriter(buf)
+	w.BeginSentence()
+	for _, word := range []string{"/login", "=name=admin", "=password=" + long, ".tag=r1", "?type=ether", "?#|"} {
+		w.WriteWord(word)
+	}
+	require.NoError(t, w.EndSentence())
+
+	c, err := NewReader(buf).ReadCommand()
+	require.NoError(t, err)
+	require.Equal(t, "r1", c.Tag)
+	require.Equal(t, long, c.Map["password"])
+	require.Equal(t, []string{"type=ether", "#|"}, c.Query)
+	require.Equal(t, "/login @r1 [{`name` `admin`} {`password` `"+long+"`}] ?[`type=ether` `#|`]", c.String())
+}
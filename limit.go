@@ -0,0 +1,232 @@
+package routeros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrLimited is returned instead of waiting for a limit when the context was made with FailFast.
+var ErrLimited = errors.New("client limit reached")
+
+// RateLimits restrict what the client sends, to spare devices that do not cope with many
+// commands at once. Zero values mean no limit.
+type RateLimits struct {
+	// CommandsPerSecond is the rate of commands sent to the device.
+	CommandsPerSecond float64
+	// Burst is the number of commands that can be sent at once before the rate applies, 1 if zero.
+	Burst int
+	// MaxInFlight is the number of async commands waiting for their reply. Listeners are not counted.
+	MaxInFlight int
+	// MaxListeners is the number of listen commands running at once.
+	MaxListeners int
+}
+
+// RateStats tell how the client is doing against its RateLimits.
+type RateStats struct {
+	InFlight  int
+	Listeners int
+	// Waiting is the number of commands waiting for a limit right now.
+	Waiting int
+	// Delayed and Rejected count the commands that had to wait, and those that failed with
+	// ErrLimited, since the client was created.
+	Delayed  uint64
+	Rejected uint64
+}
+
+type failFastKey struct{}
+
+// FailFast returns a context making the commands run with it fail with ErrLimited when a limit
+// is reached, instead of waiting.
+func FailFast(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failFastKey{}, true)
+}
+
+func isFailFast(ctx context.Context) bool {
+	v, _ := ctx.Value(failFastKey{}).(bool)
+	return v
+}
+
+// slot is what a command holds while it runs.
+type slot int
+
+const (
+	slotNone slot = iota
+	slotCommand
+	slotListener
+)
+
+type limiter struct {
+	mu     sync.Mutex
+	limits RateLimits
+	stats  RateStats
+
+	tokens float64
+	last   time.Time
+	// changed is closed when a slot is released or the limits change, to wake the waiters.
+	changed chan struct{}
+}
+
+// SetRateLimits replaces the limits of the client. It can be called at any time, and the
+// commands waiting are checked against the new limits.
+func (c *Client) SetRateLimits(l RateLimits) {
+	c.logger().Debug("SetRateLimits",
+		slog.Float64("commands-per-second", l.CommandsPerSecond), slog.Int("burst", l.Burst),
+		slog.Int("max-in-flight", l.MaxInFlight), slog.Int("max-listeners", l.MaxListeners))
+
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+
+	c.limiter.limits = l
+	c.limiter.tokens = math.Min(c.limiter.tokens, float64(c.limiter.burst()))
+	c.limiter.notify()
+}
+
+// RateLimits returns the limits set with SetRateLimits.
+func (c *Client) RateLimits() RateLimits {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+
+	return c.limiter.limits
+}
+
+// RateStats returns the current use of the limits.
+func (c *Client) RateStats() RateStats {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+
+	return c.limiter.stats
+}
+
+// acquire waits until a command holding s can be sent, or fails if ctx is done first or was
+// made with FailFast.
+func (c *Client) acquire(ctx context.Context, s slot) error {
+	l := &c.limiter
+	waiting := false
+	defer func() {
+		if waiting {
+			l.mu.Lock()
+			l.stats.Waiting--
+			l.mu.Unlock()
+		}
+	}()
+
+	for {
+		l.mu.Lock()
+		wait, reason := l.take(s, time.Now())
+		if reason == "" {
+			l.mu.Unlock()
+			return nil
+		}
+
+		if isFailFast(ctx) {
+			l.stats.Rejected++
+			l.mu.Unlock()
+
+			c.logger().Debug("command rejected", slog.String("limit", reason))
+			return fmt.Errorf("%w: %s", ErrLimited, reason)
+		}
+
+		if !waiting {
+			waiting = true
+			l.stats.Waiting++
+			l.stats.Delayed++
+			c.logger().Debug("command waiting", slog.String("limit", reason))
+		}
+
+		if l.changed == nil {
+			l.changed = make(chan struct{})
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timerC:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// release frees the slot held by a finished command.
+func (c *Client) release(s slot) {
+	if s == slotNone {
+		return
+	}
+
+	l := &c.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch s {
+	case slotCommand:
+		l.stats.InFlight--
+	case slotListener:
+		l.stats.Listeners--
+	}
+	l.notify()
+}
+
+// take holds s and a token if both are free. Otherwise it returns the limit that was reached,
+// and how long to wait for a token, if that is the reason.
+func (l *limiter) take(s slot, now time.Time) (time.Duration, string) {
+	switch {
+	case s == slotCommand && l.limits.MaxInFlight > 0 && l.stats.InFlight >= l.limits.MaxInFlight:
+		return 0, "in-flight"
+	case s == slotListener && l.limits.MaxListeners > 0 && l.stats.Listeners >= l.limits.MaxListeners:
+		return 0, "listeners"
+	}
+
+	if rate := l.limits.CommandsPerSecond; rate > 0 {
+		if l.last.IsZero() {
+			l.tokens = float64(l.burst())
+		} else {
+			l.tokens = math.Min(float64(l.burst()), l.tokens+now.Sub(l.last).Seconds()*rate)
+		}
+		l.last = now
+
+		if l.tokens < 1 {
+			return time.Duration((1 - l.tokens) / rate * float64(time.Second)), "rate"
+		}
+		l.tokens--
+	}
+
+	switch s {
+	case slotCommand:
+		l.stats.InFlight++
+	case slotListener:
+		l.stats.Listeners++
+	}
+	return 0, ""
+}
+
+func (l *limiter) burst() int {
+	if l.limits.Burst > 0 {
+		return l.limits.Burst
+	}
+	return 1
+}
+
+func (l *limiter) notify() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
@@ -0,0 +1,205 @@
+package routeros
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitsInFlight(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	c.Async()
+	c.SetRateLimits(RateLimits{MaxInFlight: 1})
+
+	sent, reply := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/system/identity/print @r1 []")
+		close(sent)
+		<-reply
+		s.writeSentence(t, "!done", ".tag=r1")
+		s.readSentence(t, "/system/resource/print @r2 []")
+		s.writeSentence(t, "!done", ".tag=r2")
+	}()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Run("/system/identity/print")
+		first <- err
+	}()
+	<-sent
+	require.Equal(t, 1, c.RateStats().InFlight)
+
+	_, err := c.RunContext(FailFast(context.Background()), "/system/resource/print")
+	require.ErrorIs(t, err, ErrLimited)
+	require.EqualError(t, err, "client limit reached: in-flight")
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Run("/system/resource/print")
+		second <- err
+	}()
+	require.Eventually(t, func() bool { return c.RateStats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	close(reply)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+	require.Equal(t, RateStats{Delayed: 1, Rejected: 1}, c.RateStats())
+}
+
+func TestRateLimitsRate(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	c.SetRateLimits(RateLimits{CommandsPerSecond: 20, Burst: 2})
+
+	go func() {
+		defer deferCloser(t, s)
+		for i := 0; i < 3; i++ {
+			s.readSentence(t, "/system/identity/print @ []")
+			s.writeSentence(t, "!done")
+		}
+	}()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := c.Run("/system/identity/print")
+		require.NoError(t, err)
+	}
+	// the burst goes out at once, the third command waits for the next token
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	_, err := c.RunContext(FailFast(context.Background()), "/system/identity/print")
+	require.EqualError(t, err, "client limit reached: rate")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = c.RunContext(ctx, "/system/identity/print")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Equal(t, RateStats{Delayed: 2, Rejected: 1}, c.RateStats())
+}
+
+func TestRateLimitsListeners(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	c.SetRateLimits(RateLimits{MaxListeners: 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/interface/listen @l1 []")
+		<-done
+		s.writeSentence(t, "!done", ".tag=l1")
+		s.readSentence(t, "/ip/address/listen @l2 []")
+		s.writeSentence(t, "!done", ".tag=l2")
+	}()
+
+	l, err := c.Listen("/interface/listen")
+	require.NoError(t, err)
+
+	_, err = c.ListenContext(FailFast(context.Background()), "/ip/address/listen")
+	require.EqualError(t, err, "client limit reached: listeners")
+
+	close(done)
+	for range l.Chan() {
+	}
+	require.Equal(t, 0, c.RateStats().Listeners)
+
+	l, err = c.Listen("/ip/address/listen")
+	require.NoError(t, err)
+	for range l.Chan() {
+	}
+	require.NoError(t, l.Err())
+}
+
+func TestSetRateLimitsWakesWaiters(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	c.Async()
+	c.SetRateLimits(RateLimits{MaxInFlight: 1})
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/system/identity/print @r1 []")
+		s.readSentence(t, "/system/resource/print @r2 []")
+		s.writeSentence(t, "!done", ".tag=r2")
+		s.writeSentence(t, "!done", ".tag=r1")
+	}()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Run("/system/identity/print")
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return c.RateStats().InFlight == 1 }, time.Second, time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Run("/system/resource/print")
+		second <- err
+	}()
+	require.Eventually(t, func() bool { return c.RateStats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	c.SetRateLimits(RateLimits{MaxInFlight: 2})
+	require.NoError(t, <-second)
+	require.NoError(t, <-first)
+	require.Equal(t, RateLimits{MaxInFlight: 2}, c.RateLimits())
+}
+
+func TestWriteErrorAfterLoopEnded(t *testing.T) {
+	for name, send := range map[string]func(c *Client) error{
+		"run": func(c *Client) error {
+			_, err := c.Run("/system/identity/print")
+			return err
+		},
+		"listen": func(c *Client) error {
+			_, err := c.Listen("/interface/listen")
+			return err
+		},
+		"batch": func(c *Client) error {
+			_, err := c.RunBatch(context.Background(), [][]string{{"/system/identity/print"}})
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, s := newPair(t)
+			defer deferCloser(t, c)
+
+			c.Async()
+			server := s.Closer.(*conn)
+
+			errC := make(chan error, 1)
+			go func() {
+				errC <- send(c)
+			}()
+
+			// nobody reads the sentence, so it is still being written when the reads end and
+			// the async loop closes the reply
+			time.Sleep(10 * time.Millisecond)
+			require.NoError(t, server.PipeWriter.Close())
+			require.Eventually(t, func() bool {
+				st := c.RateStats()
+				return st.InFlight == 0 && st.Listeners == 0
+			}, time.Second, time.Millisecond)
+
+			require.NoError(t, server.PipeReader.Close())
+			select {
+			case err := <-errC:
+				require.Error(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("the write did not fail")
+			}
+
+			st := c.RateStats()
+			require.Equal(t, 0, st.InFlight, "command released twice")
+			require.Equal(t, 0, st.Listeners, "listener released twice")
+		})
+	}
+}
@@ -23,6 +23,8 @@ type ListenReply struct {
 	chanReply
 	Done *proto.Sentence
 	c    *Client
+	// done is closed with the reply, so that nothing waits on its context anymore
+	done chan struct{}
 }
 
 // Chan returns a channel for receiving !re RouterOS sentences.
@@ -67,16 +69,21 @@ func (c *Client) ListenArgsQueue(sentence []string, queueSize int) (*ListenReply
 }
 
 // ListenArgsQueueContext sends a sentence to the RouterOS device and returns immediately.
+// It first waits for a free listener if SetRateLimits set MaxListeners.
 func (c *Client) ListenArgsQueueContext(ctx context.Context, sentence []string, queueSize int) (*ListenReply, error) {
-	c.logger().Debug("ListenArgsQueueContext", slog.Any("sentences", sentence))
+	c.logger().Debug("ListenArgsQueueContext", slog.Any("sentences", words(sentence)))
 
 	if !c.IsAsync() {
 		c.AsyncContext(ctx)
 	}
 
+	if err := c.acquire(ctx, slotListener); err != nil {
+		return nil, err
+	}
+
 	tag := c.incrementTag()
 
-	l := &ListenReply{c: c}
+	l := &ListenReply{c: c, done: make(chan struct{})}
 	l.tag = fmt.Sprintf("l%d", tag)
 	l.reC = make(chan *proto.Sentence, queueSize)
 
@@ -89,28 +96,30 @@ func (c *Client) ListenArgsQueueContext(ctx context.Context, sentence []string,
 	}
 	c.w.WriteWord(".tag=" + l.tag)
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if err := c.w.EndSentence(); err != nil {
+	if removed, err := c.endTaggedSentence(l.tag, l); err != nil {
+		if removed {
+			c.release(slotListener)
+		}
 		return nil, err
 	}
 
-	if c.tags == nil {
-		return nil, errAsyncLoopEnded
-	}
-
-	c.tags[l.tag] = l
-
 	go func() {
-		<-ctx.Done()
-
-		c.r.Cancel()
+		select {
+		case <-ctx.Done():
+			c.r.Cancel()
+		case <-l.done:
+		}
 	}()
 
 	return l, nil
 }
 
+func (l *ListenReply) close(err error) {
+	l.c.release(slotListener)
+	l.chanReply.close(err)
+	close(l.done)
+}
+
 func (l *ListenReply) processSentence(sen *proto.Sentence) (bool, error) {
 	switch sen.Word {
 	case reSentence:
@@ -2,6 +2,15 @@ package routeros
 
 import (
 	"log/slog"
+
+	"github.com/go-routeros/routeros/v3/proto"
 )
 
 type LogHandler slog.Handler
+
+// words logs the words of a sentence with the values of secrets redacted.
+type words []string
+
+func (w words) LogValue() slog.Value {
+	return slog.AnyValue(proto.RedactWords(w))
+}
@@ -0,0 +1,141 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnencodable is returned when a word contains a character that the target charset cannot represent.
+var ErrUnencodable = errors.New("character cannot be encoded")
+
+// Decoder converts the raw value of the attribute key, as sent by the device, into a Go string.
+type Decoder func(key string, value []byte) string
+
+// Encoder converts the value of the attribute key into the bytes sent to the device.
+type Encoder func(key, value string) ([]byte, error)
+
+// Charmap is a single-byte charset whose lower half is ASCII, such as the Windows codepages
+// RouterOS uses for comments and other free-form text entered from a terminal.
+type Charmap struct {
+	name string
+	high [128]rune
+	enc  map[rune]byte
+}
+
+var (
+	// Windows1251 is the Windows Cyrillic codepage.
+	Windows1251 = newCharmap("windows-1251", [128]rune{
+		0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+		0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+		0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+		0x0098, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+		0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+		0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+		0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+		0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+		0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+		0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+		0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+		0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+		0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+		0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+		0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+		0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+	})
+
+	// Windows1252 is the Windows Western European codepage.
+	Windows1252 = newCharmap("windows-1252", [128]rune{
+		0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+		0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+		0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+		0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+		0x00A0, 0x00A1, 0x00A2, 0x00A3, 0x00A4, 0x00A5, 0x00A6, 0x00A7,
+		0x00A8, 0x00A9, 0x00AA, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x00AF,
+		0x00B0, 0x00B1, 0x00B2, 0x00B3, 0x00B4, 0x00B5, 0x00B6, 0x00B7,
+		0x00B8, 0x00B9, 0x00BA, 0x00BB, 0x00BC, 0x00BD, 0x00BE, 0x00BF,
+		0x00C0, 0x00C1, 0x00C2, 0x00C3, 0x00C4, 0x00C5, 0x00C6, 0x00C7,
+		0x00C8, 0x00C9, 0x00CA, 0x00CB, 0x00CC, 0x00CD, 0x00CE, 0x00CF,
+		0x00D0, 0x00D1, 0x00D2, 0x00D3, 0x00D4, 0x00D5, 0x00D6, 0x00D7,
+		0x00D8, 0x00D9, 0x00DA, 0x00DB, 0x00DC, 0x00DD, 0x00DE, 0x00DF,
+		0x00E0, 0x00E1, 0x00E2, 0x00E3, 0x00E4, 0x00E5, 0x00E6, 0x00E7,
+		0x00E8, 0x00E9, 0x00EA, 0x00EB, 0x00EC, 0x00ED, 0x00EE, 0x00EF,
+		0x00F0, 0x00F1, 0x00F2, 0x00F3, 0x00F4, 0x00F5, 0x00F6, 0x00F7,
+		0x00F8, 0x00F9, 0x00FA, 0x00FB, 0x00FC, 0x00FD, 0x00FE, 0x00FF,
+	})
+)
+
+func newCharmap(name string, high [128]rune) *Charmap {
+	c := &Charmap{name: name, high: high, enc: make(map[rune]byte, len(high))}
+	for i, r := range high {
+		c.enc[r] = byte(0x80 + i)
+	}
+	return c
+}
+
+func (c *Charmap) String() string {
+	return c.name
+}
+
+// Decode converts b from the charset into UTF-8.
+func (c *Charmap) Decode(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, ch := range b {
+		if ch < 0x80 {
+			sb.WriteByte(ch)
+			continue
+		}
+		sb.WriteRune(c.high[ch-0x80])
+	}
+	return sb.String()
+}
+
+// Encode converts s from UTF-8 into the charset.
+func (c *Charmap) Encode(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for i, r := range s {
+		if r < 0x80 {
+			b = append(b, byte(r))
+			continue
+		}
+		ch, ok := c.enc[r]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q at offset %d to %s", ErrUnencodable, r, i, c.name)
+		}
+		b = append(b, ch)
+	}
+	return b, nil
+}
+
+// Decoder returns a Decoder that decodes the values of the given attributes (all of them if none
+// are given) from the charset. Values that are already valid UTF-8 are left untouched, as newer
+// RouterOS versions may store text in UTF-8.
+func (c *Charmap) Decoder(keys ...string) Decoder {
+	return func(key string, value []byte) string {
+		if len(keys) > 0 && !slices.Contains(keys, key) {
+			return string(value)
+		}
+		if utf8.Valid(value) {
+			return string(value)
+		}
+		return c.Decode(value)
+	}
+}
+
+// Encoder returns an Encoder that encodes the values of the given attributes (all of them if none
+// are given) into the charset. Values that are not valid UTF-8, such as binary file contents, are
+// not text and are sent as they are.
+func (c *Charmap) Encoder(keys ...string) Encoder {
+	return func(key, value string) ([]byte, error) {
+		if len(keys) > 0 && !slices.Contains(keys, key) {
+			return []byte(value), nil
+		}
+		if !utf8.ValidString(value) {
+			return []byte(value), nil
+		}
+		return c.Encode(value)
+	}
+}
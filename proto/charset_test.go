@@ -0,0 +1,100 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharmapRoundTrip(t *testing.T) {
+	for _, d := range []struct {
+		charmap *Charmap
+		text    string
+		raw     []byte
+	}{
+		{Windows1251, "Привет, мир", []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2, ',', ' ', 0xEC, 0xE8, 0xF0}},
+		{Windows1251, "Ёж №1", []byte{0xA8, 0xE6, ' ', 0xB9, '1'}},
+		{Windows1252, "Café €5", []byte{'C', 'a', 'f', 0xE9, ' ', 0x80, '5'}},
+	} {
+		t.Run(d.charmap.String()+" "+d.text, func(t *testing.T) {
+			require.Equal(t, d.text, d.charmap.Decode(d.raw))
+
+			raw, err := d.charmap.Encode(d.text)
+			require.NoError(t, err)
+			require.Equal(t, d.raw, raw)
+		})
+	}
+}
+
+func TestCharmapAllBytes(t *testing.T) {
+	for _, c := range []*Charmap{Windows1251, Windows1252} {
+		raw := make([]byte, 256)
+		for i := range raw {
+			raw[i] = byte(i)
+		}
+
+		enc, err := c.Encode(c.Decode(raw))
+		require.NoError(t, err)
+		require.Equal(t, raw, enc, c.String())
+	}
+}
+
+func TestCharmapUnencodable(t *testing.T) {
+	_, err := Windows1252.Encode("Привет")
+	require.ErrorIs(t, err, ErrUnencodable)
+}
+
+func TestCharmapDecoder(t *testing.T) {
+	dec := Windows1251.Decoder("comment")
+
+	require.Equal(t, "Привет", dec("comment", []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2}))
+	require.Equal(t, "\xCF\xF0", dec("contents", []byte{0xCF, 0xF0}), "only listed keys are decoded")
+	require.Equal(t, "уже UTF-8", dec("comment", []byte("уже UTF-8")), "valid UTF-8 is kept")
+}
+
+func TestCharmapEncoder(t *testing.T) {
+	enc := Windows1251.Encoder("comment")
+
+	b, err := enc("comment", "Привет")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2}, b)
+
+	b, err = enc("name", "Привет")
+	require.NoError(t, err)
+	require.Equal(t, []byte("Привет"), b, "only listed keys are encoded")
+
+	b, err = Windows1251.Encoder()("contents", "\x00\xff")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0xff}, b, "invalid UTF-8 is kept")
+}
+
+func TestReadWriteCharset(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetEncoder(Windows1251.Encoder())
+	w.BeginSentence()
+	w.WriteWord("/system/identity/set")
+	w.WriteWord("=name=日本")
+	require.ErrorIs(t, w.EndSentence(), ErrUnencodable)
+	require.Zero(t, buf.Len(), "nothing is written when encoding fails")
+
+	// the error does not stick to the writer
+	w.BeginSentence()
+	w.WriteWord("!re")
+	w.WriteWord("=comment=Привет")
+	w.WriteWord("=contents=\x00\xff")
+	require.NoError(t, w.EndSentence())
+	require.Contains(t, buf.String(), "!re")
+	require.Contains(t, buf.String(), "=comment=\xCF\xF0\xE8\xE2\xE5\xF2")
+
+	r := NewReader(buf)
+	r.SetDecoder(Windows1251.Decoder())
+	sen, err := r.ReadSentence()
+	require.NoError(t, err)
+	require.Equal(t, "Привет", sen.Map["comment"])
+
+	raw, ok := sen.Bytes("comment")
+	require.True(t, ok)
+	require.Equal(t, []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2}, raw)
+}
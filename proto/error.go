@@ -0,0 +1,30 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrReservedControlByte = errors.New("reserved control byte")
+	ErrWordTooLong         = errors.New("word is too long")
+	ErrTooManyWords        = errors.New("too many words in sentence")
+	ErrSentenceTooLong     = errors.New("sentence is too long")
+	ErrInvalidWord         = errors.New("invalid RouterOS sentence word")
+)
+
+// ProtocolError records a malformed or oversized element of the RouterOS API stream.
+// Offset is the position in the stream, counted from the first byte read by the Reader,
+// where the offending length prefix or word begins.
+type ProtocolError struct {
+	Offset int64
+	Err    error
+}
+
+func (err *ProtocolError) Error() string {
+	return fmt.Sprintf("RouterOS protocol error at offset %d: %s", err.Offset, err.Err)
+}
+
+func (err *ProtocolError) Unwrap() error {
+	return err.Err
+}
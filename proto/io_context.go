@@ -1,103 +1,328 @@
 package proto
 
 import (
-	"bufio"
+	"crypto/tls"
+	"errors"
 	"io"
+	"os"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// aLongTimeAgo is a non-zero time, far in the past, used to interrupt blocked I/O via deadlines.
+var aLongTimeAgo = time.Unix(1, 0)
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// tlsConn is implemented by *tls.Conn and the connections of other TLS implementations, such as
+// tlsconfig.AnonymousDHClient.
+type tlsConn interface {
+	ConnectionState() tls.ConnectionState
+}
+
 type ctxResult struct {
 	num int
 	err error
 }
 
+// ctxReader makes reads from the underlying reader cancelable.
+//
+// When the underlying reader supports read deadlines (e.g. net.Conn), Cancel and Close
+// interrupt a blocked Read by moving the deadline into the past, so no goroutines are
+// spawned and no bytes are lost. Otherwise every Read is performed in a goroutine whose
+// result is kept until the next Read if the call was interrupted.
 type ctxReader struct {
-	io.Reader
-	close atomic.Bool
-	done  chan struct{}
+	r  io.Reader
+	dl readDeadliner
+
+	closed atomic.Bool
+	// a pending Cancel is kept in canceled in deadline mode, and in cancelC in fallback mode
+	canceled atomic.Bool
+	cancelC  chan struct{}
+	closeC   chan struct{}
+	once     sync.Once
+
+	// fallback mode state
+	pending chan ctxResult
+	buf     []byte
+	rest    []byte
 }
 
-func (c *ctxReader) Close() {
-	if c.close.Load() {
-		return
+func newCtxReader(r io.Reader) *ctxReader {
+	c := &ctxReader{
+		r:       r,
+		cancelC: make(chan struct{}, 1),
+		closeC:  make(chan struct{}),
 	}
-	c.close.Store(true)
-	close(c.done)
+
+	if dl, ok := r.(readDeadliner); ok && dl.SetReadDeadline(time.Time{}) == nil {
+		c.dl = dl
+	}
+
+	return c
+}
+
+// Close interrupts the current Read and makes all the following ones return io.EOF.
+func (c *ctxReader) Close() {
+	c.once.Do(func() {
+		c.closed.Store(true)
+		close(c.closeC)
+
+		if c.dl != nil {
+			_ = c.dl.SetReadDeadline(aLongTimeAgo)
+		}
+	})
 }
 
+// Cancel interrupts the current (or the next) Read, which returns io.EOF.
 func (c *ctxReader) Cancel() {
-	if c.close.Load() {
+	if c.closed.Load() {
 		return
 	}
 
+	if c.dl != nil {
+		c.canceled.Store(true)
+		_ = c.dl.SetReadDeadline(aLongTimeAgo)
+		return
+	}
+
+	// in fallback mode the token is the only cancel state, so a Cancel is consumed exactly once
 	select {
-	case c.done <- struct{}{}:
+	case c.cancelC <- struct{}{}:
 	default:
 	}
 }
 
+// consumeCancel reports whether a Cancel is pending and resets it.
+func (c *ctxReader) consumeCancel() bool {
+	if c.dl != nil {
+		return c.canceled.Swap(false)
+	}
+
+	select {
+	case <-c.cancelC:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *ctxReader) Read(p []byte) (int, error) {
-	out := make(chan *ctxResult, 1)
-	buf := make([]byte, len(p))
+	if c.closed.Load() {
+		return 0, io.EOF
+	}
 
-	go func() {
-		res := new(ctxResult)
-		res.num, res.err = c.Reader.Read(buf)
-		out <- res
-		close(out)
-	}()
+	if c.dl == nil {
+		return c.readFallback(p)
+	}
+
+	n, err := c.r.Read(p)
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		if c.closed.Load() {
+			return n, io.EOF
+		}
+
+		if c.consumeCancel() {
+			_ = c.dl.SetReadDeadline(time.Time{})
+			return n, io.EOF
+		}
+	}
+
+	return n, err
+}
+
+func (c *ctxReader) readFallback(p []byte) (int, error) {
+	// data left over from an earlier read into a bigger buffer
+	if len(c.rest) > 0 {
+		n := copy(p, c.rest)
+		c.rest = c.rest[n:]
+		return n, nil
+	}
+
+	if c.consumeCancel() {
+		return 0, io.EOF
+	}
+
+	if c.pending == nil {
+		if cap(c.buf) < len(p) {
+			c.buf = make([]byte, len(p))
+		}
+		buf := c.buf[:len(p)]
+
+		out := make(chan ctxResult, 1)
+		c.pending = out
+
+		go func() {
+			n, err := c.r.Read(buf)
+			out <- ctxResult{num: n, err: err}
+		}()
+	}
 
 	select {
-	case <-c.done:
+	case <-c.closeC:
 		return 0, io.EOF
-	case r := <-out:
-		copy(p, buf)
+	case <-c.cancelC:
+		// the read keeps running, its result is returned by the next Read
+		return 0, io.EOF
+	case res := <-c.pending:
+		c.pending = nil
+
+		n := copy(p, c.buf[:res.num])
+		c.rest = c.buf[n:res.num]
 
-		return r.num, r.err
+		return n, res.err
 	}
 }
 
+// ctxWriter makes writes to the underlying writer cancelable, the same way ctxReader does for reads.
+//
+// A write timeout leaves a TLS connection unusable, so TLS connections are always canceled in the
+// fallback mode, where an interrupted write still completes in the background.
 type ctxWriter struct {
-	*bufio.Writer
-	close atomic.Bool
-	done  chan struct{}
+	w  io.Writer
+	dl writeDeadliner
+
+	closed atomic.Bool
+	// a pending Cancel is kept in canceled in deadline mode, and in cancelC in fallback mode
+	canceled atomic.Bool
+	cancelC  chan struct{}
+	closeC   chan struct{}
+	once     sync.Once
+
+	// fallback mode state
+	pending chan ctxResult
 }
 
-func (c *ctxWriter) Close() {
-	if c.close.Load() {
-		return
+func newCtxWriter(w io.Writer) *ctxWriter {
+	c := &ctxWriter{
+		w:       w,
+		cancelC: make(chan struct{}, 1),
+		closeC:  make(chan struct{}),
 	}
-	c.close.Store(true)
-	close(c.done)
+
+	if _, ok := w.(tlsConn); ok {
+		return c
+	}
+
+	if dl, ok := w.(writeDeadliner); ok && dl.SetWriteDeadline(time.Time{}) == nil {
+		c.dl = dl
+	}
+
+	return c
+}
+
+// Close interrupts the current Write and makes all the following ones return io.EOF.
+func (c *ctxWriter) Close() {
+	c.once.Do(func() {
+		c.closed.Store(true)
+		close(c.closeC)
+
+		if c.dl != nil {
+			_ = c.dl.SetWriteDeadline(aLongTimeAgo)
+		}
+	})
 }
 
+// Cancel interrupts the current (or the next) Write, which returns io.EOF.
 func (c *ctxWriter) Cancel() {
-	if c.close.Load() {
+	if c.closed.Load() {
 		return
 	}
 
+	if c.dl != nil {
+		c.canceled.Store(true)
+		_ = c.dl.SetWriteDeadline(aLongTimeAgo)
+		return
+	}
+
+	// in fallback mode the token is the only cancel state, so a Cancel is consumed exactly once
 	select {
-	case c.done <- struct{}{}:
+	case c.cancelC <- struct{}{}:
 	default:
 	}
 }
 
+// consumeCancel reports whether a Cancel is pending and resets it.
+func (c *ctxWriter) consumeCancel() bool {
+	if c.dl != nil {
+		return c.canceled.Swap(false)
+	}
+
+	select {
+	case <-c.cancelC:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *ctxWriter) Write(p []byte) (int, error) {
-	out := make(chan *ctxResult, 1)
+	if c.closed.Load() {
+		return 0, io.EOF
+	}
+
+	if c.dl == nil {
+		return c.writeFallback(p)
+	}
+
+	n, err := c.w.Write(p)
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		if c.closed.Load() {
+			return n, io.EOF
+		}
+
+		if c.consumeCancel() {
+			_ = c.dl.SetWriteDeadline(time.Time{})
+			return n, io.EOF
+		}
+	}
+
+	return n, err
+}
+
+func (c *ctxWriter) writeFallback(p []byte) (int, error) {
+	// wait for an interrupted write to finish, so writes are never reordered
+	if c.pending != nil {
+		select {
+		case <-c.closeC:
+			return 0, io.EOF
+		case <-c.cancelC:
+			return 0, io.EOF
+		case <-c.pending:
+			c.pending = nil
+		}
+	}
+
+	if c.consumeCancel() {
+		return 0, io.EOF
+	}
+
+	// p may be reused by the caller once Write returns, so the goroutine needs its own copy
 	buf := make([]byte, len(p))
 	copy(buf, p)
 
+	out := make(chan ctxResult, 1)
+	c.pending = out
+
 	go func() {
-		res := new(ctxResult)
-		res.num, res.err = c.Writer.Write(buf)
-		out <- res
-		close(out)
+		n, err := c.w.Write(buf)
+		out <- ctxResult{num: n, err: err}
 	}()
 
 	select {
-	case <-c.done:
+	case <-c.closeC:
+		return 0, io.EOF
+	case <-c.cancelC:
 		return 0, io.EOF
-	case r := <-out:
-		return r.num, r.err
+	case res := <-out:
+		c.pending = nil
+		return res.num, res.err
 	}
 }
@@ -0,0 +1,174 @@
+package proto
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderUsesDeadlines(t *testing.T) {
+	a, b := net.Pipe()
+	defer func() { require.NoError(t, a.Close()) }()
+	defer func() { require.NoError(t, b.Close()) }()
+
+	require.NotNil(t, newCtxReader(a).dl, "net.Conn should be canceled using deadlines")
+	require.Nil(t, newCtxReader(&bytes.Buffer{}).dl, "bytes.Buffer has no deadlines")
+}
+
+func TestReaderCancel(t *testing.T) {
+	for name, pipe := range map[string]func() (io.ReadCloser, io.WriteCloser){
+		"deadline": func() (io.ReadCloser, io.WriteCloser) { return net.Pipe() },
+		"fallback": func() (io.ReadCloser, io.WriteCloser) { return io.Pipe() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			pr, pw := pipe()
+			defer func() { _ = pr.Close() }()
+			defer func() { _ = pw.Close() }()
+
+			r := NewReader(pr)
+
+			errC := make(chan error, 1)
+			go func() {
+				_, err := r.ReadSentence()
+				errC <- err
+			}()
+
+			time.Sleep(10 * time.Millisecond)
+			r.Cancel()
+
+			select {
+			case err := <-errC:
+				require.ErrorIs(t, err, io.EOF)
+			case <-time.After(time.Second):
+				t.Fatal("ReadSentence was not interrupted by Cancel")
+			}
+
+			// the stream must stay usable and in sync after Cancel
+			go func() {
+				w := NewWriter(pw)
+				w.BeginSentence()
+				w.WriteWord("!done")
+				w.WriteWord("=ret=abc")
+				_ = w.EndSentence()
+			}()
+
+			sen, err := r.ReadSentence()
+			require.NoError(t, err)
+			require.Equal(t, "!done @ [{`ret` `abc`}]", sen.String())
+		})
+	}
+}
+
+func TestReaderClose(t *testing.T) {
+	a, b := net.Pipe()
+	defer func() { require.NoError(t, a.Close()) }()
+	defer func() { require.NoError(t, b.Close()) }()
+
+	r := NewReader(a)
+	r.Close()
+	r.Close()
+
+	_, err := r.ReadSentence()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestWriterUsesDeadlines(t *testing.T) {
+	a, b := net.Pipe()
+	defer func() { require.NoError(t, a.Close()) }()
+	defer func() { require.NoError(t, b.Close()) }()
+
+	require.NotNil(t, newCtxWriter(a).dl, "net.Conn should be canceled using deadlines")
+	require.Nil(t, newCtxWriter(tls.Client(a, &tls.Config{})).dl, "a write timeout breaks a TLS connection")
+}
+
+func TestWriterCancel(t *testing.T) {
+	for name, pipe := range map[string]func() (io.ReadCloser, io.WriteCloser){
+		"deadline": func() (io.ReadCloser, io.WriteCloser) { return net.Pipe() },
+		"fallback": func() (io.ReadCloser, io.WriteCloser) { return io.Pipe() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			pr, pw := pipe()
+			defer func() { _ = pr.Close() }()
+			defer func() { _ = pw.Close() }()
+
+			// nobody reads from pr, so the write blocks until canceled
+			w := NewWriter(pw)
+
+			errC := make(chan error, 1)
+			go func() {
+				w.BeginSentence()
+				w.WriteWord("/system/resource/print")
+				errC <- w.EndSentence()
+			}()
+
+			time.Sleep(10 * time.Millisecond)
+			w.Cancel()
+
+			select {
+			case err := <-errC:
+				require.ErrorIs(t, err, io.EOF)
+			case <-time.After(time.Second):
+				t.Fatal("EndSentence was not interrupted by Cancel")
+			}
+
+			// nothing of the sentence was sent, so w must stay usable
+			go func() {
+				w.BeginSentence()
+				w.WriteWord("/system/identity/print")
+				errC <- w.EndSentence()
+			}()
+
+			r := NewReader(pr)
+			if name == "fallback" {
+				// the interrupted write completes in the background, before the next one
+				sen, err := r.ReadSentence()
+				require.NoError(t, err)
+				require.Equal(t, "/system/resource/print @ []", sen.String())
+			}
+
+			sen, err := r.ReadSentence()
+			require.NoError(t, err)
+			require.Equal(t, "/system/identity/print @ []", sen.String())
+			require.NoError(t, <-errC)
+		})
+	}
+}
+
+func TestWriterCancelPartway(t *testing.T) {
+	a, b := net.Pipe()
+	defer func() { require.NoError(t, a.Close()) }()
+	defer func() { require.NoError(t, b.Close()) }()
+
+	w := NewWriter(a)
+
+	errC := make(chan error, 1)
+	go func() {
+		w.BeginSentence()
+		w.WriteWord("/system/script/add")
+		w.WriteWord("=source=" + strings.Repeat(":put x;", 100))
+		errC <- w.EndSentence()
+	}()
+
+	// the device receives the start of the sentence only
+	_, err := io.ReadFull(b, make([]byte, 3))
+	require.NoError(t, err)
+	w.Cancel()
+
+	select {
+	case err := <-errC:
+		require.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		t.Fatal("EndSentence was not interrupted by Cancel")
+	}
+
+	// the device would read the next sentence as the rest of the first one
+	w.BeginSentence()
+	w.WriteWord("/system/identity/print")
+	require.ErrorIs(t, w.EndSentence(), io.EOF)
+}
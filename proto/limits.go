@@ -0,0 +1,19 @@
+package proto
+
+// Limits restricts what a Reader accepts from the device, so a corrupt or hostile peer cannot
+// force huge allocations. A zero field means no limit.
+type Limits struct {
+	// MaxWordSize is the maximum length of a single word in bytes.
+	MaxWordSize int64
+	// MaxWords is the maximum number of words in a sentence, including the reply word.
+	MaxWords int
+	// MaxSentenceSize is the maximum total length of the words of a sentence in bytes.
+	MaxSentenceSize int64
+}
+
+// DefaultLimits are used by NewReader.
+var DefaultLimits = Limits{
+	MaxWordSize:     16 << 20,
+	MaxWords:        1 << 16,
+	MaxSentenceSize: 64 << 20,
+}
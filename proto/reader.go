@@ -5,76 +5,164 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
+	"sync/atomic"
 )
 
+// readChunk is the largest buffer allocated ahead of the data actually received for a word.
+const readChunk = 64 << 10
+
 // Reader reads sentences from a RouterOS device.
 type Reader interface {
 	ReadSentence() (*Sentence, error)
+	// SetLimits replaces the limits the Reader enforces. By default DefaultLimits are used.
+	SetLimits(l Limits)
+	// SetDecoder sets the function converting attribute values into strings. A nil Decoder,
+	// the default, keeps the bytes as they are.
+	SetDecoder(d Decoder)
 	Cancel()
 	Close()
 }
 
 type reader struct {
-	*ctxReader
+	*bufio.Reader
+	ctx *ctxReader
+
+	limits  atomic.Pointer[Limits]
+	decoder atomic.Pointer[Decoder]
+	// off is the number of bytes consumed from the stream so far.
+	off int64
 }
 
 // NewReader returns a new Reader to read from r.
+// If r supports read deadlines (e.g. net.Conn), they are used to interrupt blocked reads on Cancel and Close.
 func NewReader(r io.Reader) Reader {
-	return &reader{
-		ctxReader: &ctxReader{Reader: bufio.NewReader(r), done: make(chan struct{})},
+	ctx := newCtxReader(r)
+
+	rd := &reader{
+		Reader: bufio.NewReader(ctx),
+		ctx:    ctx,
 	}
+	rd.SetLimits(DefaultLimits)
+
+	return rd
+}
+
+// SetLimits replaces the limits the reader enforces.
+func (r *reader) SetLimits(l Limits) {
+	r.limits.Store(&l)
+}
+
+// SetDecoder sets the function converting attribute values into strings.
+func (r *reader) SetDecoder(d Decoder) {
+	if d == nil {
+		r.decoder.Store(nil)
+		return
+	}
+	r.decoder.Store(&d)
+}
+
+// Cancel interrupts the current (or the next) ReadSentence.
+func (r *reader) Cancel() {
+	r.ctx.Cancel()
+}
+
+// Close interrupts the current ReadSentence and makes all the following ones fail.
+func (r *reader) Close() {
+	r.ctx.Close()
 }
 
 // ReadSentence reads a sentence.
 func (r *reader) ReadSentence() (*Sentence, error) {
+	limits := r.limits.Load()
+	decoder := r.decoder.Load()
+
 	sen := NewSentence()
+	var words int
+	var size int64
 	for {
-		b, err := r.readWord()
+		start := r.off
+
+		maxLen, tooLong := int64(-1), ErrWordTooLong
+		if limits.MaxWordSize > 0 {
+			maxLen = limits.MaxWordSize
+		}
+		if left := limits.MaxSentenceSize - size; limits.MaxSentenceSize > 0 && (maxLen < 0 || left < maxLen) {
+			maxLen, tooLong = left, ErrSentenceTooLong
+		}
+
+		b, err := r.readWord(maxLen, tooLong)
 		if err != nil {
 			return nil, err
 		}
 		if len(b) == 0 {
 			return sen, nil
 		}
+
+		words++
+		size += int64(len(b))
+		if limits.MaxWords > 0 && words > limits.MaxWords {
+			return nil, &ProtocolError{Offset: start, Err: ErrTooManyWords}
+		}
+
 		// Ex.: !re, !done
 		if sen.Word == "" {
 			sen.Word = string(b)
 			continue
 		}
-		// Command tag.
-		if bytes.HasPrefix(b, []byte(".tag=")) {
-			sen.Tag = string(b[5:])
-			continue
-		}
 		// Ex.: =key=value, =key
 		if bytes.HasPrefix(b, []byte("=")) {
 			t := bytes.SplitN(b[1:], []byte("="), 2)
 			if len(t) == 1 {
 				t = append(t, []byte{})
 			}
-			p := Pair{string(t[0]), string(t[1])}
+			p := Pair{Key: string(t[0])}
+			if decoder != nil {
+				p.Value = (*decoder)(p.Key, t[1])
+			} else {
+				p.Value = string(t[1])
+			}
 			sen.List = append(sen.List, p)
 			sen.Map[p.Key] = p.Value
+			sen.Raw = append(sen.Raw, t[1])
 			continue
 		}
-		return nil, fmt.Errorf("invalid RouterOS sentence word: %#q", b)
+		// Command tag or API attribute. Ex.: .tag=r1, .section=1, .about=...
+		if bytes.HasPrefix(b, []byte(".")) {
+			t := bytes.SplitN(b, []byte("="), 2)
+			if len(t) == 1 {
+				t = append(t, []byte{})
+			}
+			// Command tag.
+			if string(t[0]) == ".tag" {
+				sen.Tag = string(t[1])
+				continue
+			}
+			sen.Attrs = append(sen.Attrs, Pair{string(t[0]), string(t[1])})
+			continue
+		}
+		return nil, &ProtocolError{Offset: start, Err: fmt.Errorf("%w: %#q", ErrInvalidWord, b)}
 	}
 }
 
 func (r *reader) readNumber(size int) (int64, error) {
-	b := make([]byte, size)
-	_, err := io.ReadFull(r, b)
-	if err != nil {
-		return -1, err
-	}
 	var num int64
-	for _, ch := range b {
+	for i := 0; i < size; i++ {
+		ch, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && i > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return -1, err
+		}
+		r.off++
 		num = num<<8 | int64(ch)
 	}
 	return num, nil
 }
 
 func (r *reader) readLength() (int64, error) {
+	start := r.off
 	l, err := r.readNumber(1)
 	if err != nil {
 		return -1, err
@@ -93,6 +181,9 @@ func (r *reader) readLength() (int64, error) {
 		l = l & ^0xF0 << 24 | n
 	case l&0xF8 == 0xF0:
 		l, err = r.readNumber(4)
+	default:
+		// 0xF8 and above are reserved for control bytes
+		return -1, &ProtocolError{Offset: start, Err: fmt.Errorf("%w: 0x%02X", ErrReservedControlByte, l)}
 	}
 	if err != nil {
 		return -1, err
@@ -100,15 +191,28 @@ func (r *reader) readLength() (int64, error) {
 	return l, nil
 }
 
-func (r *reader) readWord() ([]byte, error) {
+// readWord reads one word. If maxLen is not negative, longer words are rejected with tooLong.
+func (r *reader) readWord(maxLen int64, tooLong error) ([]byte, error) {
+	start := r.off
 	l, err := r.readLength()
 	if err != nil {
 		return nil, err
 	}
-	b := make([]byte, l)
-	_, err = io.ReadFull(r, b)
-	if err != nil {
-		return nil, err
+	if maxLen >= 0 && l > maxLen {
+		return nil, &ProtocolError{Offset: start, Err: fmt.Errorf("%w: %d bytes", tooLong, l)}
+	}
+
+	// grow the buffer as the data arrives instead of trusting the length prefix up front
+	b := make([]byte, 0, min(l, readChunk))
+	for int64(len(b)) < l {
+		n := int(min(l-int64(len(b)), readChunk))
+		b = slices.Grow(b, n)
+		m, err := io.ReadFull(r, b[len(b):len(b)+n])
+		r.off += int64(m)
+		if err != nil {
+			return nil, err
+		}
+		b = b[:len(b)+n]
 	}
 	return b, nil
 }
@@ -3,7 +3,11 @@ package proto
 import (
 	"bytes"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,12 +34,140 @@ func TestReadLength(t *testing.T) {
 }
 
 func TestReadRandom(t *testing.T) {
-	randomBytes := make([]byte, 4)
+	randomBytes := make([]byte, 5)
 	_, err := rand.Read(randomBytes)
 	require.NoError(t, err, "read random bytes error")
 
+	// 0xF8 and above are reserved control bytes, not lengths
+	if randomBytes[0] >= 0xF8 {
+		randomBytes[0] = 0xF0
+	}
+
 	r := NewReader(bytes.NewBuffer(randomBytes)).(*reader)
 	_, err = r.readLength()
 	require.NoError(t, err, "read length error")
 
 }
+
+func TestReadReservedControlByte(t *testing.T) {
+	for _, b := range []byte{0xF8, 0xFC, 0xFF} {
+		t.Run(fmt.Sprintf("0x%02X", b), func(t *testing.T) {
+			r := NewReader(bytes.NewBuffer([]byte{0x05, '!', 'd', 'o', 'n', 'e', b}))
+
+			_, err := r.ReadSentence()
+			require.ErrorIs(t, err, ErrReservedControlByte)
+
+			var pe *ProtocolError
+			require.ErrorAs(t, err, &pe)
+			require.Equal(t, int64(6), pe.Offset)
+		})
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	for i, d := range []struct {
+		limits Limits
+		words  []string
+		err    error
+		offset int64
+	}{
+		{Limits{MaxWordSize: 4}, []string{"!re", "=a=bc"}, ErrWordTooLong, 4},
+		{Limits{MaxWords: 2}, []string{"!re", "=a=b", "=c=d"}, ErrTooManyWords, 9},
+		{Limits{MaxSentenceSize: 8}, []string{"!re", "=a=b", "=c=d"}, ErrSentenceTooLong, 9},
+		{Limits{MaxWordSize: 4, MaxWords: 3, MaxSentenceSize: 11}, []string{"!re", "=a=b", "=c=d"}, nil, 0},
+		{Limits{}, []string{"!re", "=a=" + strings.Repeat("x", 1<<17)}, nil, 0},
+	} {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewWriter(buf)
+			w.BeginSentence()
+			for _, word := range d.words {
+				w.WriteWord(word)
+			}
+			require.NoError(t, w.EndSentence())
+
+			r := NewReader(buf)
+			r.SetLimits(d.limits)
+
+			_, err := r.ReadSentence()
+			if d.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, d.err)
+
+			var pe *ProtocolError
+			require.ErrorAs(t, err, &pe)
+			require.Equal(t, d.offset, pe.Offset)
+		})
+	}
+}
+
+func TestReadHugeLengthDoesNotAllocate(t *testing.T) {
+	r := NewReader(bytes.NewBuffer([]byte{0xF0, 0x7F, 0xFF, 0xFF, 0xFF, 'x'}))
+	r.SetLimits(Limits{})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := r.ReadSentence()
+	runtime.ReadMemStats(&after)
+
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "2 GiB length prefix should not be allocated up front")
+}
+
+func FuzzReadSentence(f *testing.F) {
+	for _, words := range [][]string{
+		{"!done"},
+		{"!re", ".tag=l1", "=address=1.2.3.4/32", "=only-key"},
+		{"!trap", "=message=" + strings.Repeat("x", 200)},
+		{"!re", ".section=1", "=.id=*1", "=.dead=yes"},
+	} {
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf)
+		w.BeginSentence()
+		for _, word := range words {
+			w.WriteWord(word)
+		}
+		require.NoError(f, w.EndSentence())
+		f.Add(buf.Bytes())
+	}
+	f.Add([]byte{0xF8})
+	f.Add([]byte{0xF0, 0xFF, 0xFF, 0xFF, 0xFF})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := NewReader(bytes.NewReader(data))
+		r.SetLimits(Limits{MaxWordSize: 1 << 16, MaxWords: 1 << 10, MaxSentenceSize: 1 << 18})
+
+		sen, err := r.ReadSentence()
+		if err != nil {
+			var pe *ProtocolError
+			if errors.As(err, &pe) {
+				require.GreaterOrEqual(t, pe.Offset, int64(0))
+				require.Less(t, pe.Offset, int64(len(data)))
+			}
+			return
+		}
+
+		// whatever was decoded must survive a round trip
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf)
+		w.BeginSentence()
+		w.WriteWord(sen.Word)
+		if sen.Tag != "" {
+			w.WriteWord(".tag=" + sen.Tag)
+		}
+		for _, p := range sen.List {
+			w.WriteWord("=" + p.Key + "=" + p.Value)
+		}
+		for _, p := range sen.Attrs {
+			w.WriteWord(p.Key + "=" + p.Value)
+		}
+		require.NoError(t, w.EndSentence())
+
+		again, err := NewReader(buf).ReadSentence()
+		require.NoError(t, err)
+		require.Equal(t, sen.String(), again.String())
+	})
+}
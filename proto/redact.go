@@ -0,0 +1,78 @@
+package proto
+
+import "strings"
+
+// Redacted replaces the values of secret attributes in Sentence.String and RedactWords.
+const Redacted = "***"
+
+// SecretKeys are the parts of attribute names whose values are secrets, e.g. the password of
+// /login or the wpa2-pre-shared-key of a security profile.
+var SecretKeys = []string{"password", "secret", "passphrase", "pre-shared-key", "private-key", "authentication-key"}
+
+// IsSecret reports whether the value of the attribute key is a secret.
+func IsSecret(key string) bool {
+	for _, s := range SecretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactWords returns words with the values of secret attributes and queries replaced by
+// Redacted. Empty values are kept, as they tell nothing. words is returned as is when it has
+// no secret.
+func RedactWords(words []string) []string {
+	return replace(words, func(word string) (string, bool) {
+		if len(word) < 2 || (word[0] != '=' && word[0] != '?') {
+			return "", false
+		}
+		r, ok := redactAttr(word[1:])
+		return word[:1] + r, ok
+	})
+}
+
+// redactAttr redacts key=value if the value is a secret.
+func redactAttr(s string) (string, bool) {
+	key, v, ok := strings.Cut(s, "=")
+	if !ok || v == "" || !IsSecret(key) {
+		return "", false
+	}
+	return key + "=" + Redacted, true
+}
+
+// replace returns words with the ones f changes replaced, copying words only if needed.
+func replace(words []string, f func(string) (string, bool)) []string {
+	var out []string
+	for i, word := range words {
+		r, ok := f(word)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]string(nil), words...)
+		}
+		out[i] = r
+	}
+	if out == nil {
+		return words
+	}
+	return out
+}
+
+func redactPairs(pairs []Pair) []Pair {
+	var out []Pair
+	for i, p := range pairs {
+		if p.Value == "" || !IsSecret(p.Key) {
+			continue
+		}
+		if out == nil {
+			out = append([]Pair(nil), pairs...)
+		}
+		out[i].Value = Redacted
+	}
+	if out == nil {
+		return pairs
+	}
+	return out
+}
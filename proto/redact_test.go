@@ -0,0 +1,35 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactWords(t *testing.T) {
+	words := []string{"/login", "=name=admin", "=password=hunter2"}
+	require.Equal(t, []string{"/login", "=name=admin", "=password=***"}, RedactWords(words))
+	require.Equal(t, "=password=hunter2", words[2], "words modified")
+
+	require.Equal(t,
+		[]string{"/interface/wireless/security-profiles/set", "=wpa2-pre-shared-key=***", "=comment=secret-free"},
+		RedactWords([]string{"/interface/wireless/security-profiles/set", "=wpa2-pre-shared-key=x", "=comment=secret-free"}))
+	require.Equal(t, []string{"/ppp/secret/print", "?password=***"}, RedactWords([]string{"/ppp/secret/print", "?password=x"}))
+	require.Equal(t, []string{"/system/backup/load", "=password="}, RedactWords([]string{"/system/backup/load", "=password="}))
+}
+
+func TestSentenceStringRedacted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.BeginSentence()
+	for _, word := range []string{"!re", "=name=vpn", "=password=hunter2"} {
+		w.WriteWord(word)
+	}
+	require.NoError(t, w.EndSentence())
+
+	sen, err := NewReader(buf).ReadSentence()
+	require.NoError(t, err)
+	require.Equal(t, "!re @ [{`name` `vpn`} {`password` `***`}]", sen.String())
+	require.Equal(t, "hunter2", sen.Map["password"])
+}
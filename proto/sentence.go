@@ -9,8 +9,22 @@ type Sentence struct {
 	Tag  string
 	List []Pair
 	Map  map[string]string
+	// Raw has the undecoded bytes of every value in List, in the same order.
+	Raw [][]byte
+	// Attrs has the API attribute words (.section, .about, ...) other than .tag, in the
+	// order they were received. Keys keep their leading dot.
+	Attrs []Pair
 }
 
+// API attribute names.
+const (
+	AttrID      = ".id"
+	AttrNextID  = ".nextid"
+	AttrDead    = ".dead"
+	AttrSection = ".section"
+	AttrAbout   = ".about"
+)
+
 type Pair struct {
 	Key, Value string
 }
@@ -21,6 +35,64 @@ func NewSentence() *Sentence {
 	}
 }
 
+// String formats the sentence for logs and tests, with the values of secret attributes
+// replaced by Redacted.
 func (sen *Sentence) String() string {
-	return fmt.Sprintf("%s @%s %#q", sen.Word, sen.Tag, sen.List)
+	if len(sen.Attrs) > 0 {
+		return fmt.Sprintf("%s @%s %#q %#q", sen.Word, sen.Tag, redactPairs(sen.List), sen.Attrs)
+	}
+	return fmt.Sprintf("%s @%s %#q", sen.Word, sen.Tag, redactPairs(sen.List))
+}
+
+// Attr returns the value of the API attribute key (e.g. ".section"). RouterOS sends some of them
+// as regular attributes (=.id=*1, =.dead=yes), so those are looked up too.
+func (sen *Sentence) Attr(key string) (string, bool) {
+	for i := len(sen.Attrs) - 1; i >= 0; i-- {
+		if sen.Attrs[i].Key == key {
+			return sen.Attrs[i].Value, true
+		}
+	}
+	v, ok := sen.Map[key]
+	return v, ok
+}
+
+// ID returns the internal item id (.id), e.g. "*1F".
+func (sen *Sentence) ID() string {
+	v, _ := sen.Attr(AttrID)
+	return v
+}
+
+// NextID returns the id of the next item (.nextid), used when moving items.
+func (sen *Sentence) NextID() string {
+	v, _ := sen.Attr(AttrNextID)
+	return v
+}
+
+// IsDead reports whether the sentence tells that the item was removed (.dead=yes), as sent by listen.
+func (sen *Sentence) IsDead() bool {
+	v, ok := sen.Attr(AttrDead)
+	return ok && (v == "" || v == "yes" || v == "true")
+}
+
+// Section returns the section number (.section) of print and export replies.
+func (sen *Sentence) Section() string {
+	v, _ := sen.Attr(AttrSection)
+	return v
+}
+
+// About returns the informational message (.about) attached to the sentence.
+func (sen *Sentence) About() string {
+	v, _ := sen.Attr(AttrAbout)
+	return v
+}
+
+// Bytes returns the undecoded value of the attribute key, as sent by the device.
+// As with Map, the last value wins if the key is repeated.
+func (sen *Sentence) Bytes(key string) ([]byte, bool) {
+	for i := len(sen.List) - 1; i >= 0; i-- {
+		if sen.List[i].Key == key && i < len(sen.Raw) {
+			return sen.Raw[i], true
+		}
+	}
+	return nil, false
 }
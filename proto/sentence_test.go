@@ -41,3 +41,65 @@ func TestReadWrite(t *testing.T) {
 		})
 	}
 }
+
+func TestSentenceBytes(t *testing.T) {
+	raw := []byte{0x00, 0xC0, 0xFF, '='}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.BeginSentence()
+	w.WriteWord("!re")
+	w.WriteWord("=contents=" + string(raw))
+	w.WriteWord("=name=a")
+	w.WriteWord("=name=b")
+	require.NoError(t, w.EndSentence())
+
+	sen, err := NewReader(buf).ReadSentence()
+	require.NoError(t, err)
+
+	b, ok := sen.Bytes("contents")
+	require.True(t, ok)
+	require.Equal(t, raw, b)
+	require.Equal(t, string(raw), sen.Map["contents"], "without a decoder the value keeps its bytes")
+
+	b, ok = sen.Bytes("name")
+	require.True(t, ok)
+	require.Equal(t, []byte("b"), b, "the last value wins, as in Map")
+
+	_, ok = sen.Bytes("missing")
+	require.False(t, ok)
+}
+
+func TestSentenceAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.BeginSentence()
+	for _, word := range []string{"!re", ".tag=l1", ".section=2", "=.id=*1F", "=name=ether1", ".about=managed by CAPsMAN", "=.dead=yes"} {
+		w.WriteWord(word)
+	}
+	require.NoError(t, w.EndSentence())
+
+	sen, err := NewReader(buf).ReadSentence()
+	require.NoError(t, err)
+
+	require.Equal(t, "l1", sen.Tag)
+	require.Equal(t, []Pair{{".section", "2"}, {".about", "managed by CAPsMAN"}}, sen.Attrs)
+	require.Equal(t, []Pair{{".id", "*1F"}, {"name", "ether1"}, {".dead", "yes"}}, sen.List)
+
+	require.Equal(t, "*1F", sen.ID())
+	require.Equal(t, "2", sen.Section())
+	require.Equal(t, "managed by CAPsMAN", sen.About())
+	require.True(t, sen.IsDead())
+	require.Equal(t, "", sen.NextID())
+
+	require.Equal(t, "!re @l1 [{`.id` `*1F`} {`name` `ether1`} {`.dead` `yes`}] [{`.section` `2`} {`.about` `managed by CAPsMAN`}]", sen.String())
+}
+
+func TestSentenceNotDead(t *testing.T) {
+	sen := NewSentence()
+	sen.Map[".id"] = "*1"
+	require.False(t, sen.IsDead())
+
+	sen.Map[".dead"] = "false"
+	require.False(t, sen.IsDead())
+}
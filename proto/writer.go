@@ -1,8 +1,9 @@
 package proto
 
 import (
-	"bufio"
+	"fmt"
 	"io"
+	"strings"
 	"sync"
 )
 
@@ -11,21 +12,49 @@ type Writer interface {
 	BeginSentence()
 	WriteWord(word string)
 	EndSentence() error
+	// SetEncoder sets the function converting attribute values into bytes. A nil Encoder,
+	// the default, sends the words as they are.
+	SetEncoder(e Encoder)
 
 	Cancel()
 	Close()
 }
 
 type writer struct {
-	*ctxWriter
+	ctx *ctxWriter
 
-	err error
+	err      error
+	encoder  Encoder
+	sentence []string
 	sync.Mutex
 }
 
 // NewWriter returns a new Writer to write to w.
+// If w supports write deadlines (e.g. net.Conn), they are used to interrupt blocked writes on Cancel and Close.
 func NewWriter(w io.Writer) Writer {
-	return &writer{ctxWriter: &ctxWriter{Writer: bufio.NewWriter(w), done: make(chan struct{})}}
+	ctx := newCtxWriter(w)
+
+	return &writer{ctx: ctx}
+}
+
+// Cancel interrupts the current (or the next) sentence being written, whose EndSentence returns io.EOF.
+// If part of the sentence was already sent, the device would read the next sentence as its continuation,
+// so w is closed; otherwise w stays usable.
+func (w *writer) Cancel() {
+	w.ctx.Cancel()
+}
+
+// Close interrupts the current write and makes all the following ones fail.
+func (w *writer) Close() {
+	w.ctx.Close()
+}
+
+// SetEncoder sets the function converting attribute values into bytes. It waits for the sentence being written to end.
+func (w *writer) SetEncoder(e Encoder) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.encoder = e
 }
 
 // BeginSentence prepares w for writing a sentence.
@@ -33,40 +62,70 @@ func (w *writer) BeginSentence() {
 	w.Lock()
 }
 
-// EndSentence writes the end-of-sentence marker (an empty word).
-// It returns the first error that occurred on calls to methods on w.
+// EndSentence encodes the words of the sentence and writes them, followed by the end-of-sentence marker
+// (an empty word), at once. If the Encoder fails, nothing is written and its error is returned.
+// Once a write has failed partway through a sentence, all the following ones fail with the same error.
 func (w *writer) EndSentence() error {
 	defer w.Unlock()
-	w.WriteWord("")
-	w.flush()
-	return w.err
+
+	words, err := w.encode()
+	w.sentence = w.sentence[:0]
+	if err != nil {
+		return err
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	var b []byte
+	for _, word := range words {
+		b = append(b, encodeLength(len(word))...)
+		b = append(b, word...)
+	}
+	b = append(b, encodeLength(0)...)
+
+	n, err := w.ctx.Write(b)
+	if err != nil && n > 0 {
+		// the rest of the sentence will never follow what the device has already received
+		w.err = fmt.Errorf("sentence cut off after %d of %d bytes: %w", n, len(b), err)
+		w.ctx.Close()
+		return w.err
+	}
+	return err
 }
 
-// WriteWord writes one word.
+// WriteWord adds one word to the sentence.
 func (w *writer) WriteWord(word string) {
-	b := []byte(word)
-	w.write(encodeLength(len(b)))
-	w.write(b)
+	w.sentence = append(w.sentence, word)
 }
 
-func (w *writer) flush() {
-	if w.err != nil {
-		return
-	}
-	err := w.Flush()
-	if err != nil {
-		w.err = err
+// encode converts the words of the sentence into bytes, passing the values of attributes
+// (=key=value) through the Encoder.
+func (w *writer) encode() ([][]byte, error) {
+	words := make([][]byte, len(w.sentence))
+	for i, word := range w.sentence {
+		key, value, ok := cutAttribute(word)
+		if w.encoder == nil || !ok {
+			words[i] = []byte(word)
+			continue
+		}
+
+		b, err := w.encoder(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", key, err)
+		}
+		words[i] = append([]byte("="+key+"="), b...)
 	}
+	return words, nil
 }
 
-func (w *writer) write(b []byte) {
-	if w.err != nil {
-		return
-	}
-	_, err := w.Write(b)
-	if err != nil {
-		w.err = err
+// cutAttribute splits a word of the form =key=value.
+func cutAttribute(word string) (key, value string, ok bool) {
+	rest, ok := strings.CutPrefix(word, "=")
+	if !ok {
+		return "", "", false
 	}
+	return strings.Cut(rest, "=")
 }
 
 func encodeLength(l int) []byte {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/go-routeros/routeros/v3/internal/wire"
 	"github.com/go-routeros/routeros/v3/proto"
 )
 
@@ -157,7 +158,7 @@ func TestAsyncTwice(t *testing.T) {
 	errC := c.Async()
 	err := <-errC
 	require.EqualError(t, err, errAlreadyAsync.Error())
-	require.NoError(t, <-errC, errAsyncLoopEnded.Error())
+	require.NoError(t, <-errC, ErrAsyncLoopEnded.Error())
 }
 
 func TestProtoRun(t *testing.T) {
@@ -406,20 +407,20 @@ func newPair(t *testing.T) (*Client, *fakeServer) {
 	require.NoError(t, err)
 
 	return c, &fakeServer{
-		proto.NewReader(br),
+		wire.NewReader(br),
 		proto.NewWriter(aw),
 		&conn{br, aw},
 	}
 }
 
 type fakeServer struct {
-	r proto.Reader
+	r *wire.Reader
 	w proto.Writer
 	io.Closer
 }
 
 func (f *fakeServer) readSentence(t *testing.T, want string) {
-	sen, err := f.r.ReadSentence()
+	sen, err := f.r.ReadCommand()
 	require.NoError(t, err)
 	require.Equal(t, want, sen.String(), "wrong sentence")
 	t.Logf("< %s\n", sen)
@@ -0,0 +1,130 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times Backoff sends a command when MaxAttempts is zero.
+	DefaultMaxAttempts = 4
+	// DefaultInitial is the wait before the first retry when Backoff.Initial is zero.
+	DefaultInitial = 100 * time.Millisecond
+	// DefaultMax is the longest wait between retries when Backoff.Max is zero.
+	DefaultMax = 5 * time.Second
+)
+
+// Policy decides which failed commands are sent again, and when.
+type Policy interface {
+	// Retry is called when sentence failed with err on the given attempt, 1 for the first.
+	// It returns how long to wait before sending sentence again, or false to give up.
+	Retry(sentence []string, attempt int, err error) (time.Duration, bool)
+}
+
+// DefaultPolicy retries the commands IsSafe accepts after the errors IsTransient accepts.
+var DefaultPolicy Policy = &Backoff{Jitter: 0.2}
+
+// Backoff retries commands with exponentially longer waits between attempts.
+type Backoff struct {
+	// MaxAttempts is the number of times a command is sent, DefaultMaxAttempts if zero.
+	MaxAttempts int
+	// Initial is the wait before the first retry, DefaultInitial if zero.
+	Initial time.Duration
+	// Max caps the waits, DefaultMax if zero.
+	Max time.Duration
+	// Multiplier is the growth of the wait after each attempt, 2 if zero.
+	Multiplier float64
+	// Jitter randomizes every wait by up to this fraction of it, in either direction, so that
+	// clients failing together do not retry together.
+	Jitter float64
+	// Safe tells the commands that may be sent again, IsSafe if nil.
+	Safe func(sentence []string) bool
+	// Transient tells the errors worth a retry, IsTransient if nil.
+	Transient func(err error) bool
+}
+
+// Retry implements Policy.
+func (b *Backoff) Retry(sentence []string, attempt int, err error) (time.Duration, bool) {
+	maxAttempts, safe, transient := b.MaxAttempts, b.Safe, b.Transient
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if safe == nil {
+		safe = IsSafe
+	}
+	if transient == nil {
+		transient = IsTransient
+	}
+	if attempt >= maxAttempts || !safe(sentence) || !transient(err) {
+		return 0, false
+	}
+
+	initial, maxWait, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = DefaultInitial
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultMax
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	wait := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(maxWait))
+	if b.Jitter > 0 {
+		wait += wait * b.Jitter * (2*rand.Float64() - 1) //nolint:gosec
+	}
+	return time.Duration(wait), true
+}
+
+// IsSafe reports whether sentence only reads from the device, so that sending it twice does no
+// harm: print and getall, unless they write a file, and monitor commands with once.
+func IsSafe(sentence []string) bool {
+	if len(sentence) == 0 {
+		return false
+	}
+
+	cmd := sentence[0]
+	switch cmd[strings.LastIndexByte(cmd, '/')+1:] {
+	case "print", "getall":
+		_, file := arg(sentence, "file")
+		return !file
+	case "monitor", "monitor-traffic":
+		v, once := arg(sentence, "once")
+		return once && v != "no" && v != "false"
+	}
+	return false
+}
+
+func arg(sentence []string, name string) (string, bool) {
+	for _, word := range sentence[1:] {
+		if v, ok := strings.CutPrefix(word, "="+name+"="); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// IsTransient reports whether err comes from the connection rather than from the device, such
+// as a reset connection or a network timeout. Errors of the context are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, routeros.ErrAsyncLoopEnded)
+}
@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+func TestIsSafe(t *testing.T) {
+	for _, tc := range []struct {
+		sentence []string
+		want     bool
+	}{
+		{[]string{"/ip/address/print"}, true},
+		{[]string{"/ip/address/print", "?interface=ether1"}, true},
+		{[]string{"/system/resource/getall"}, true},
+		{[]string{"/interface/ethernet/monitor", "=numbers=ether1", "=once="}, true},
+		{[]string{"/interface/monitor-traffic", "=interface=ether1", "=once=yes"}, true},
+		{[]string{"/interface/ethernet/monitor", "=numbers=ether1"}, false},
+		{[]string{"/interface/ethernet/monitor", "=numbers=ether1", "=once=no"}, false},
+		{[]string{"/ip/address/print", "=file=addresses"}, false},
+		{[]string{"/ip/address/add", "=address=10.0.0.1/24"}, false},
+		{[]string{"/system/reboot"}, false},
+		{nil, false},
+	} {
+		t.Run(fmt.Sprint(tc.sentence), func(t *testing.T) {
+			require.Equal(t, tc.want, IsSafe(tc.sentence))
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	require.True(t, IsTransient(io.EOF))
+	require.True(t, IsTransient(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)))
+	require.True(t, IsTransient(routeros.ErrAsyncLoopEnded))
+	require.False(t, IsTransient(nil))
+	require.False(t, IsTransient(context.Canceled))
+	require.False(t, IsTransient(&routeros.DeviceError{Sentence: proto.NewSentence()}))
+	require.False(t, IsTransient(errors.New("other")))
+}
+
+func TestBackoff(t *testing.T) {
+	b := &Backoff{Initial: 100 * time.Millisecond, Max: 300 * time.Millisecond}
+	cmd := []string{"/ip/address/print"}
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		wait, ok := b.Retry(cmd, attempt+1, io.EOF)
+		require.True(t, ok)
+		require.Equal(t, want, wait)
+	}
+
+	_, ok := b.Retry(cmd, DefaultMaxAttempts, io.EOF)
+	require.False(t, ok, "retried after the last attempt")
+	_, ok = b.Retry([]string{"/ip/address/add"}, 1, io.EOF)
+	require.False(t, ok, "retried an unsafe command")
+	_, ok = b.Retry(cmd, 1, errors.New("other"))
+	require.False(t, ok, "retried an error of the device")
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait, ok := b.Retry(cmd, 1, io.EOF)
+		require.True(t, ok)
+		require.InDelta(t, 100*time.Millisecond, wait, float64(50*time.Millisecond))
+	}
+
+	b.Safe = func([]string) bool { return true }
+	_, ok = b.Retry([]string{"/ip/address/add"}, 1, io.EOF)
+	require.True(t, ok, "custom classification ignored")
+}
@@ -0,0 +1,139 @@
+/*
+Package retry sends commands again when they fail because of the connection, such as a
+network blip resetting it. Only commands that can safely run twice are retried: by default
+those reading from the device, see IsSafe. A Policy may classify commands and errors
+differently.
+
+After a connection error, a Client with a Dial function closes the broken connection and
+dials a new one before the next attempt. Without one, connection errors are returned at once:
+the connection may still carry the rest of the failed reply, or be unable to write.
+*/
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+// ErrNoConn is returned when a Client has neither a connection nor a way to dial one.
+var ErrNoConn = errors.New("no connection to the device")
+
+// Options configure a Client.
+type Options struct {
+	// Dial connects to the device again after a connection error. Without it, commands failing
+	// because of the connection are not retried.
+	Dial func(ctx context.Context) (*routeros.Client, error)
+	// Policy decides which commands are retried, DefaultPolicy if nil.
+	Policy Policy
+	// OnRetry is called before every retry, e.g. for logging.
+	OnRetry func(sentence []string, attempt int, err error, wait time.Duration)
+}
+
+// Client runs commands through a routeros.Client, retrying them as its Policy says.
+// It is safe for concurrent use.
+type Client struct {
+	opts Options
+
+	mu sync.Mutex
+	c  *routeros.Client
+}
+
+// New returns a Client using c. c may be nil if opts.Dial is set, the connection is then
+// dialed by the first command.
+func New(c *routeros.Client, opts Options) *Client {
+	if opts.Policy == nil {
+		opts.Policy = DefaultPolicy
+	}
+	return &Client{opts: opts, c: c}
+}
+
+// Conn returns the current connection, dialing one if needed.
+func (r *Client) Conn(ctx context.Context) (*routeros.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.c == nil {
+		if r.opts.Dial == nil {
+			return nil, ErrNoConn
+		}
+		c, err := r.opts.Dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.c = c
+	}
+	return r.c, nil
+}
+
+// Close closes the current connection.
+func (r *Client) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.c == nil {
+		return nil
+	}
+	err := r.c.Close()
+	r.c = nil
+	return err
+}
+
+// Run simply calls RunArgs().
+func (r *Client) Run(ctx context.Context, sentence ...string) (*routeros.Reply, error) {
+	return r.RunArgs(ctx, sentence)
+}
+
+// RunArgs runs sentence until it succeeds, the Policy gives up or ctx is done. The error
+// returned is that of the last attempt.
+func (r *Client) RunArgs(ctx context.Context, sentence []string) (*routeros.Reply, error) {
+	for attempt := 1; ; attempt++ {
+		reply, err := r.run(ctx, sentence)
+		if err == nil || ctx.Err() != nil || (IsTransient(err) && r.opts.Dial == nil) {
+			return reply, err
+		}
+
+		wait, ok := r.opts.Policy.Retry(sentence, attempt, err)
+		if !ok {
+			return reply, err
+		}
+		if r.opts.OnRetry != nil {
+			r.opts.OnRetry(sentence, attempt, err, wait)
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return reply, err
+		case <-t.C:
+		}
+	}
+}
+
+func (r *Client) run(ctx context.Context, sentence []string) (*routeros.Reply, error) {
+	c, err := r.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.RunArgsContext(ctx, sentence)
+	if IsTransient(err) && r.opts.Dial != nil {
+		r.discard(c)
+	}
+	return reply, err
+}
+
+// discard closes c, unless another command has already replaced it.
+func (r *Client) discard(c *routeros.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.c == c {
+		_ = c.Close()
+		r.c = nil
+	}
+}
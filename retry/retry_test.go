@@ -0,0 +1,120 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestRunReconnects(t *testing.T) {
+	c1, s1 := routerostest.NewPair(t)
+	s1.Serve(t, func() {
+		s1.ReadSentence(t, "/ip/address/print @ []")
+		// the connection breaks before the reply
+		_ = s1.Close()
+	})
+
+	c2, s2 := routerostest.NewPair(t)
+	s2.Serve(t, func() {
+		s2.ReadSentence(t, "/ip/address/print @ []")
+		s2.WriteSentence(t, "!re", "=address=10.0.0.1/24")
+		s2.WriteSentence(t, "!done")
+	})
+
+	var retries []int
+	r := New(c1, Options{
+		Dial: func(context.Context) (*routeros.Client, error) {
+			return c2, nil
+		},
+		Policy: &Backoff{Initial: time.Millisecond},
+		OnRetry: func(_ []string, attempt int, err error, _ time.Duration) {
+			require.True(t, IsTransient(err))
+			retries = append(retries, attempt)
+		},
+	})
+
+	reply, err := r.Run(context.Background(), "/ip/address/print")
+	require.NoError(t, err)
+	require.Len(t, reply.Re, 1)
+	require.Equal(t, []int{1}, retries)
+
+	conn, err := r.Conn(context.Background())
+	require.NoError(t, err)
+	require.Same(t, c2, conn)
+}
+
+func TestRunUnsafe(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/address/add @ [{`address` `10.0.0.1/24`}]")
+		_ = s.Close()
+	})
+
+	r := New(c, Options{
+		Dial: func(context.Context) (*routeros.Client, error) {
+			t.Error("dialed for an unsafe command")
+			return nil, ErrNoConn
+		},
+	})
+
+	_, err := r.Run(context.Background(), "/ip/address/add", "=address=10.0.0.1/24")
+	require.Error(t, err)
+	require.True(t, IsTransient(err))
+}
+
+func TestRunDeviceError(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/bogus/print @ []")
+		s.WriteSentence(t, "!trap", "=message=no such command prefix")
+		s.WriteSentence(t, "!done")
+	})
+
+	r := New(c, Options{})
+
+	_, err := r.Run(context.Background(), "/ip/bogus/print")
+	require.EqualError(t, err, "from RouterOS device: no such command prefix")
+}
+
+func TestRunGivesUp(t *testing.T) {
+	var dials int
+	r := New(nil, Options{
+		Dial: func(context.Context) (*routeros.Client, error) {
+			dials++
+			c, s := routerostest.NewPair(t)
+			s.Serve(t, func() {
+				s.ReadSentence(t, "/system/resource/print @ []")
+				_ = s.Close()
+			})
+			return c, nil
+		},
+		Policy: &Backoff{MaxAttempts: 3, Initial: time.Millisecond},
+	})
+
+	_, err := r.Run(context.Background(), "/system/resource/print")
+	require.True(t, IsTransient(err))
+	require.Equal(t, 3, dials)
+}
+
+func TestRunNoDial(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/address/print @ []")
+		_ = s.Close()
+	})
+
+	r := New(c, Options{
+		Policy: &Backoff{Initial: time.Millisecond},
+		OnRetry: func([]string, int, error, time.Duration) {
+			t.Error("retried on the broken connection")
+		},
+	})
+
+	_, err := r.Run(context.Background(), "/ip/address/print")
+	require.True(t, IsTransient(err))
+}
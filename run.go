@@ -11,6 +11,13 @@ import (
 type asyncReply struct {
 	chanReply
 	Reply
+	c    *Client
+	slot slot
+}
+
+func (a *asyncReply) close(err error) {
+	a.c.release(a.slot)
+	a.chanReply.close(err)
 }
 
 // Run simply calls RunArgs().
@@ -29,40 +36,48 @@ func (c *Client) RunArgs(sentences []string) (*Reply, error) {
 }
 
 // RunArgsContext sends a sentence to the RouterOS device and waits for the reply.
+// The command first waits for the limits set with SetRateLimits, unless it is a /cancel.
 func (c *Client) RunArgsContext(ctx context.Context, sentences []string) (*Reply, error) {
-	c.logger().Debug("RunArgsContext", slog.Any("sentences", sentences))
+	c.logger().Debug("RunArgsContext", slog.Any("sentences", words(sentences)))
+
+	async := c.IsAsync()
+
+	// /cancel lowers the load of the device, so it is never held back
+	s := slotNone
+	if !isCancel(sentences) {
+		if async {
+			s = slotCommand
+		}
+		if err := c.acquire(ctx, s); err != nil {
+			return nil, err
+		}
+	}
 
 	c.w.BeginSentence()
 	for _, sentence := range sentences {
 		c.w.WriteWord(sentence)
 	}
 
-	if !c.IsAsync() {
+	if !async {
 		return c.runArgsContextSync()
 	}
 
 	// async mode, assign new tag to request
 	tag := c.incrementTag()
 
-	a := &asyncReply{}
+	a := &asyncReply{c: c, slot: s}
 	a.reC = make(chan *proto.Sentence)
 	a.tag = fmt.Sprintf("r%d", tag)
 	c.w.WriteWord(".tag=" + a.tag)
 	c.logger().Debug("set tag", slog.String("tag", a.tag))
-	if err := c.w.EndSentence(); err != nil {
-		return nil, err
-	}
 
-	c.mu.Lock()
-	if c.tags == nil {
-		c.mu.Unlock()
-
-		return nil, errAsyncLoopEnded
+	if removed, err := c.endTaggedSentence(a.tag, a); err != nil {
+		if removed {
+			c.release(s)
+		}
+		return nil, err
 	}
 
-	c.tags[a.tag] = a
-	c.mu.Unlock()
-
 	// wait for asyncLoop to close channel or context done
 	for {
 		select {
@@ -111,3 +126,7 @@ func (c *Client) runArgsContextSync() (*Reply, error) {
 		}
 	}
 }
+
+func isCancel(sentences []string) bool {
+	return len(sentences) > 0 && sentences[0] == "/cancel"
+}
@@ -0,0 +1,124 @@
+package safemode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/export"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+// DefaultConfirmName is the name of the scheduler job and of the files created by
+// ConfirmOrRevert when ConfirmOptions.Name is empty.
+const DefaultConfirmName = "routeros-confirm"
+
+// ErrReverting is returned by ConfirmOrRevert when the changes were not confirmed: the device
+// is restoring its previous configuration.
+var ErrReverting = errors.New("changes not confirmed, the device is reverting them")
+
+// RevertMode selects how the device restores its configuration.
+type RevertMode int
+
+const (
+	// RevertBackup saves a binary backup, and loads it to revert. It is the default.
+	RevertBackup RevertMode = iota
+	// RevertExport saves an export, and resets the configuration with it to revert. Unlike a
+	// backup, an export can be read and restored on another device of the same model.
+	RevertExport
+)
+
+// ConfirmOptions configure ConfirmOrRevert.
+type ConfirmOptions struct {
+	// Delay is the time after which the device reverts, DefaultTimeout if zero.
+	Delay time.Duration
+	// Name of the scheduler job and of the backup or export, DefaultConfirmName if empty.
+	Name string
+	Mode RevertMode
+	// Dial opens a new connection to the device, to check that it can still be reached once
+	// the changes are applied. It is required.
+	Dial func(ctx context.Context) (*routeros.Client, error)
+	// Verify runs extra checks on the new connection. By default the identity is read.
+	Verify func(ctx context.Context, c *routeros.Client) error
+}
+
+// ConfirmOrRevert applies sentences in a way that survives losing the device, or the calling
+// process: before changing anything it saves the configuration and installs a scheduler job
+// restoring it after opts.Delay. Once the sentences are applied, a new connection is opened
+// with opts.Dial and, if it works, the job is removed.
+//
+// If a sentence fails or the new connection does not work, the job is made to run at once and
+// an error wrapping ErrReverting is returned.
+func ConfirmOrRevert(ctx context.Context, c *routeros.Client, sentences [][]string, opts ConfirmOptions) error {
+	if opts.Dial == nil {
+		return errors.New("safe mode confirm: Dial is required")
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = DefaultTimeout
+	}
+	if opts.Name == "" {
+		opts.Name = DefaultConfirmName
+	}
+
+	file := opts.Name + ".backup"
+	save := []string{"/system/backup/save", "=name=" + opts.Name, "=dont-encrypt=yes"}
+	restore := "/system backup load name=" + export.Quote(file) + " password=\"\""
+	if opts.Mode == RevertExport {
+		file = opts.Name + ".rsc"
+		save = []string{"/export", "=file=" + opts.Name}
+		restore = "/system reset-configuration no-defaults=yes skip-backup=yes run-after-reset=" + export.Quote(file)
+	}
+
+	if _, err := c.RunArgsContext(ctx, save); err != nil {
+		return fmt.Errorf("safe mode confirm: %w", err)
+	}
+
+	// the job removes itself first, so that it does not run again once the device is back
+	onEvent := "/system scheduler remove [find name=" + export.Quote(opts.Name) + "]; " + restore
+	_, err := c.RunContext(ctx, "/system/scheduler/add", "=name="+opts.Name,
+		"=interval="+value.FormatDuration(opts.Delay), "=on-event="+onEvent)
+	if err != nil {
+		return fmt.Errorf("safe mode confirm: %w", err)
+	}
+
+	for _, sentence := range sentences {
+		if _, err := c.RunArgsContext(ctx, sentence); err != nil {
+			return revertNow(ctx, c, opts.Name, fmt.Errorf("%s: %w", sentence[0], err))
+		}
+	}
+
+	fresh, err := opts.Dial(ctx)
+	if err != nil {
+		return revertNow(ctx, c, opts.Name, err)
+	}
+	defer fresh.Close()
+
+	verify := opts.Verify
+	if verify == nil {
+		verify = func(ctx context.Context, c *routeros.Client) error {
+			_, err := c.RunContext(ctx, "/system/identity/print")
+			return err
+		}
+	}
+	if err := verify(ctx, fresh); err != nil {
+		return revertNow(ctx, c, opts.Name, err)
+	}
+
+	// confirmed: the new connection is known to work, so it removes the job
+	if _, err := fresh.RunContext(ctx, "/system/scheduler/remove", "=numbers="+opts.Name); err != nil {
+		return fmt.Errorf("safe mode confirm: removing scheduler job %s: %w", opts.Name, err)
+	}
+	if _, err := fresh.RunContext(ctx, "/file/remove", "=numbers="+file); err != nil {
+		return fmt.Errorf("safe mode confirm: removing %s: %w", file, err)
+	}
+	return nil
+}
+
+// revertNow makes the scheduler job run at once. If that fails, the job still runs once its
+// interval elapses.
+func revertNow(ctx context.Context, c *routeros.Client, name string, cause error) error {
+	_, _ = c.RunContext(ctx, "/system/scheduler/set", "=numbers="+name, "=interval=1s")
+	return fmt.Errorf("%w: %w", ErrReverting, cause)
+}
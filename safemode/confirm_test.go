@@ -0,0 +1,97 @@
+package safemode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestConfirm(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	fresh, fs := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/backup/save @ [{`name` `routeros-confirm`} {`dont-encrypt` `yes`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/scheduler/add @ [{`name` `routeros-confirm`} {`interval` `2m`} "+
+			"{`on-event` `/system scheduler remove [find name=routeros-confirm]; /system backup load name=routeros-confirm.backup password=\"\"`}]")
+		s.WriteSentence(t, "!done", "=ret=*1")
+		s.ReadSentence(t, "/ip/firewall/filter/add @ [{`chain` `input`} {`action` `drop`} {`in-interface` `ether1`}]")
+		s.WriteSentence(t, "!done", "=ret=*2")
+	})
+	fs.Serve(t, func() {
+		fs.ReadSentence(t, "/system/identity/print @ []")
+		fs.WriteSentence(t, "!re", "=name=router")
+		fs.WriteSentence(t, "!done")
+		fs.ReadSentence(t, "/system/scheduler/remove @ [{`numbers` `routeros-confirm`}]")
+		fs.WriteSentence(t, "!done")
+		fs.ReadSentence(t, "/file/remove @ [{`numbers` `routeros-confirm.backup`}]")
+		fs.WriteSentence(t, "!done")
+	})
+
+	err := ConfirmOrRevert(context.Background(), c, [][]string{
+		{"/ip/firewall/filter/add", "=chain=input", "=action=drop", "=in-interface=ether1"},
+	}, ConfirmOptions{
+		Delay: 2 * time.Minute,
+		Dial:  func(context.Context) (*routeros.Client, error) { return fresh, nil },
+	})
+	require.NoError(t, err)
+}
+
+func TestConfirmLockedOut(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/export @ [{`file` `pre-change`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/scheduler/add @ [{`name` `pre-change`} {`interval` `5m`} "+
+			"{`on-event` `/system scheduler remove [find name=pre-change]; /system reset-configuration no-defaults=yes skip-backup=yes run-after-reset=pre-change.rsc`}]")
+		s.WriteSentence(t, "!done", "=ret=*1")
+		s.ReadSentence(t, "/ip/service/set @ [{`numbers` `api`} {`port` `9999`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/scheduler/set @ [{`numbers` `pre-change`} {`interval` `1s`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	dialErr := errors.New("connection refused")
+	err := ConfirmOrRevert(context.Background(), c, [][]string{
+		{"/ip/service/set", "=numbers=api", "=port=9999"},
+	}, ConfirmOptions{
+		Name: "pre-change",
+		Mode: RevertExport,
+		Dial: func(context.Context) (*routeros.Client, error) { return nil, dialErr },
+	})
+	require.ErrorIs(t, err, ErrReverting)
+	require.ErrorIs(t, err, dialErr)
+}
+
+func TestConfirmCommandFails(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/backup/save @ [{`name` `x`} {`dont-encrypt` `yes`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/scheduler/add @ [{`name` `x`} {`interval` `1m`} "+
+			"{`on-event` `/system scheduler remove [find name=x]; /system backup load name=x.backup password=\"\"`}]")
+		s.WriteSentence(t, "!done", "=ret=*1")
+		s.ReadSentence(t, "/ip/route/add @ [{`gateway` `bogus`}]")
+		s.WriteSentence(t, "!trap", "=message=invalid value")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/scheduler/set @ [{`numbers` `x`} {`interval` `1s`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	err := ConfirmOrRevert(context.Background(), c, [][]string{{"/ip/route/add", "=gateway=bogus"}}, ConfirmOptions{
+		Name:  "x",
+		Delay: time.Minute,
+		Dial:  func(context.Context) (*routeros.Client, error) { t.Fatal("unexpected dial"); return nil, nil },
+	})
+	require.ErrorIs(t, err, ErrReverting)
+	require.ErrorContains(t, err, "/ip/route/add: from RouterOS device: invalid value")
+}
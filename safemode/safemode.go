@@ -0,0 +1,231 @@
+/*
+Package safemode gives API clients a safety net similar to the Safe Mode of the RouterOS
+terminal: changes made in a Session are reverted unless Commit is called before a timeout.
+
+Changes are undone with the inverses recorded by a tx.Tx. Optionally a backup is saved when
+the session starts, and loaded if some inverse fails; loading a backup reboots the device.
+
+The watchdog runs in the calling process, so it does not help if the process dies.
+ConfirmOrRevert relies on a scheduler job of the device instead.
+*/
+package safemode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/tx"
+)
+
+// DefaultTimeout is the time given to commit a session when Options.Timeout is zero.
+const DefaultTimeout = 5 * time.Minute
+
+var (
+	// ErrReverted is returned when a session is used after its changes were reverted.
+	ErrReverted = errors.New("safe mode session reverted")
+	// ErrCommitted is returned when a session is used after Commit.
+	ErrCommitted = errors.New("safe mode session committed")
+)
+
+// Options configure a Session.
+type Options struct {
+	// Timeout is the time given to call Commit, DefaultTimeout if zero.
+	Timeout time.Duration
+	// Backup is the name of a backup saved when the session starts, and loaded if the
+	// changes cannot be undone. Empty means no backup.
+	Backup string
+	// OnRevert is called, in its own goroutine, once the watchdog has reverted the session.
+	OnRevert func(report *tx.Report, err error)
+}
+
+// Session is a set of changes reverted unless committed in time. It is safe for concurrent
+// use; commands run one at a time.
+type Session struct {
+	c    *routeros.Client
+	tx   *tx.Tx
+	opts Options
+	ctx  context.Context
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	state  error
+	report *tx.Report
+	err    error
+	done   chan struct{}
+}
+
+// Start begins a session on c. ctx is used for the backup and, without its cancellation,
+// for reverting the changes.
+func Start(ctx context.Context, c *routeros.Client, opts Options) (*Session, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	if opts.Backup != "" {
+		if _, err := c.RunContext(ctx, "/system/backup/save", "=name="+opts.Backup, "=dont-encrypt=yes"); err != nil {
+			return nil, fmt.Errorf("safe mode backup: %w", err)
+		}
+	}
+
+	s := &Session{
+		c:    c,
+		tx:   tx.Begin(c),
+		opts: opts,
+		ctx:  context.WithoutCancel(ctx),
+		done: make(chan struct{}),
+	}
+	s.timer = time.AfterFunc(opts.Timeout, s.expire)
+
+	return s, nil
+}
+
+// Run simply calls RunArgs().
+func (s *Session) Run(ctx context.Context, sentence ...string) (*routeros.Reply, error) {
+	return s.RunArgs(ctx, sentence)
+}
+
+// RunArgs runs sentence in the session. See tx.Tx.RunArgs. If the command fails, the session
+// is reverted at once.
+func (s *Session) RunArgs(ctx context.Context, sentence []string) (*routeros.Reply, error) {
+	return s.run(ctx, func() (*routeros.Reply, error) {
+		return s.tx.RunArgs(ctx, sentence)
+	})
+}
+
+// RunWithInverse runs sentence in the session with an explicit inverse. See tx.Tx.RunWithInverse.
+func (s *Session) RunWithInverse(ctx context.Context, sentence []string, inverse ...[]string) (*routeros.Reply, error) {
+	return s.run(ctx, func() (*routeros.Reply, error) {
+		return s.tx.RunWithInverse(ctx, sentence, inverse...)
+	})
+}
+
+func (s *Session) run(ctx context.Context, f func() (*routeros.Reply, error)) (*routeros.Reply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		return nil, s.state
+	}
+
+	r, err := f()
+	var abort *tx.AbortError
+	if errors.As(err, &abort) {
+		// the transaction rolled back on its own
+		s.timer.Stop()
+		s.finish(abort.Rollback, s.fallback(ctx, abort.RollbackErr))
+	}
+	return r, err
+}
+
+// Extend restarts the watchdog, giving d more time to commit. It returns ErrReverted if the
+// watchdog has already fired, even if the revert is still to run.
+func (s *Session) Extend(d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		return s.state
+	}
+	if !s.timer.Stop() {
+		// expire is waiting for s.mu and will revert the session
+		return ErrReverted
+	}
+	s.timer.Reset(d)
+	return nil
+}
+
+// Commit keeps the changes and stops the watchdog. The backup, if any, is removed.
+func (s *Session) Commit(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		return s.state
+	}
+	s.timer.Stop()
+
+	s.state = ErrCommitted
+	close(s.done)
+
+	if err := s.tx.Commit(); err != nil {
+		return err
+	}
+	if s.opts.Backup != "" {
+		if _, err := s.c.RunContext(ctx, "/file/remove", "=numbers="+s.opts.Backup+".backup"); err != nil {
+			return fmt.Errorf("safe mode backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// Revert undoes the changes now and stops the watchdog.
+func (s *Session) Revert(ctx context.Context) (*tx.Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != nil {
+		return nil, s.state
+	}
+	s.timer.Stop()
+
+	return s.revert(ctx)
+}
+
+// Done returns a channel closed when the session is committed or reverted.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Result returns what reverting the session undid and the error it met, once Done is closed.
+// Both are nil for committed sessions.
+func (s *Session) Result() (*tx.Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.report, s.err
+}
+
+func (s *Session) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Commit or Revert won the race with the timer
+	if s.state != nil {
+		return
+	}
+
+	report, err := s.revert(s.ctx)
+	if s.opts.OnRevert != nil {
+		go s.opts.OnRevert(report, err)
+	}
+}
+
+// revert must be called with s.mu held.
+func (s *Session) revert(ctx context.Context) (*tx.Report, error) {
+	report, err := s.tx.Rollback(ctx)
+	s.finish(report, s.fallback(ctx, err))
+	return s.report, s.err
+}
+
+// fallback loads the backup when undoing the changes failed.
+func (s *Session) fallback(ctx context.Context, err error) error {
+	if err == nil || s.opts.Backup == "" {
+		return err
+	}
+
+	_, lerr := s.c.RunContext(ctx, "/system/backup/load", "=name="+s.opts.Backup+".backup", "=password=")
+	if lerr != nil {
+		return errors.Join(err, fmt.Errorf("safe mode backup: %w", lerr))
+	}
+	return fmt.Errorf("backup %s loaded after: %w", s.opts.Backup, err)
+}
+
+func (s *Session) finish(report *tx.Report, err error) {
+	s.state = ErrReverted
+	s.report, s.err = report, err
+	close(s.done)
+}
@@ -0,0 +1,129 @@
+package safemode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+	"github.com/go-routeros/routeros/v3/tx"
+)
+
+func TestCommit(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/filter/add @ [{`chain` `input`} {`action` `drop`}]")
+		s.WriteSentence(t, "!done", "=ret=*9")
+	})
+
+	ctx := context.Background()
+	sess, err := Start(ctx, c, Options{Timeout: time.Hour})
+	require.NoError(t, err)
+
+	_, err = sess.Run(ctx, "/ip/firewall/filter/add", "=chain=input", "=action=drop")
+	require.NoError(t, err)
+	require.NoError(t, sess.Commit(ctx))
+
+	<-sess.Done()
+	report, err := sess.Result()
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	_, err = sess.Run(ctx, "/ip/firewall/filter/add", "=chain=input")
+	require.ErrorIs(t, err, ErrCommitted)
+	require.ErrorIs(t, sess.Commit(ctx), ErrCommitted)
+}
+
+func TestWatchdog(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/firewall/filter/add @ [{`chain` `input`} {`action` `drop`}]")
+		s.WriteSentence(t, "!done", "=ret=*9")
+		s.ReadSentence(t, "/ip/firewall/filter/remove @ [{`.id` `*9`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	reverted := make(chan *tx.Report, 1)
+	ctx := context.Background()
+	sess, err := Start(ctx, c, Options{
+		Timeout:  50 * time.Millisecond,
+		OnRevert: func(report *tx.Report, err error) { reverted <- report },
+	})
+	require.NoError(t, err)
+
+	_, err = sess.Run(ctx, "/ip/firewall/filter/add", "=chain=input", "=action=drop")
+	require.NoError(t, err)
+
+	select {
+	case report := <-reverted:
+		require.Len(t, report.Undone, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("session was not reverted")
+	}
+
+	<-sess.Done()
+	_, err = sess.Result()
+	require.NoError(t, err)
+	require.ErrorIs(t, sess.Commit(ctx), ErrReverted)
+}
+
+func TestBackupFallback(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/backup/save @ [{`name` `pre`} {`dont-encrypt` `yes`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/tool/netwatch/add @ [{`host` `192.0.2.1`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/backup/load @ [{`name` `pre.backup`} {`password` ``}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	ctx := context.Background()
+	sess, err := Start(ctx, c, Options{Timeout: time.Hour, Backup: "pre"})
+	require.NoError(t, err)
+
+	_, err = sess.Run(ctx, "/tool/netwatch/add", "=host=192.0.2.1")
+	require.NoError(t, err)
+
+	report, err := sess.Revert(ctx)
+	require.ErrorIs(t, err, tx.ErrNoInverse)
+	require.ErrorContains(t, err, "backup pre loaded")
+	require.Len(t, report.Failed, 1)
+}
+
+func TestCommitRemovesBackup(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/backup/save @ [{`name` `pre`} {`dont-encrypt` `yes`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/file/remove @ [{`numbers` `pre.backup`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	ctx := context.Background()
+	sess, err := Start(ctx, c, Options{Backup: "pre"})
+	require.NoError(t, err)
+	require.NoError(t, sess.Extend(time.Hour))
+	require.NoError(t, sess.Commit(ctx))
+}
+
+func TestExtendAfterExpiry(t *testing.T) {
+	c, _ := routerostest.NewPair(t)
+
+	sess, err := Start(context.Background(), c, Options{Timeout: time.Millisecond})
+	require.NoError(t, err)
+
+	// hold the lock so that the watchdog fires but cannot revert yet
+	sess.mu.Lock()
+	time.Sleep(20 * time.Millisecond)
+	sess.mu.Unlock()
+
+	require.ErrorIs(t, sess.Extend(time.Hour), ErrReverted)
+	<-sess.Done()
+}
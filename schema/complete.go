@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Complete returns the completions of the last word of line, a command in the form of the API
+// with words separated by spaces. The first word completes to the paths of menus and commands,
+// e.g. "/ip/addr" to "/ip/address", and the others to the arguments of the command, e.g.
+// "=inter" or "" to "=interface=". Unknown menus and commands have no completions.
+func (s *Schema) Complete(ctx context.Context, line string) ([]string, error) {
+	words := strings.Split(line, " ")
+	last := words[len(words)-1]
+
+	if len(words) == 1 {
+		dir, partial := "/", strings.TrimPrefix(last, "/")
+		if i := strings.LastIndexByte(last, '/'); i > 0 {
+			dir, partial = last[:i], last[i+1:]
+		}
+		return s.complete(ctx, dir, partial, func(n *Node) string { return n.Path })
+	}
+
+	partial, ok := strings.CutPrefix(last, "=")
+	if last != "" && (!ok || strings.Contains(partial, "=")) {
+		// queries and values are not completed
+		return nil, nil
+	}
+	return s.complete(ctx, words[0], partial, func(n *Node) string { return "=" + n.Name + "=" })
+}
+
+func (s *Schema) complete(ctx context.Context, path, partial string, format func(*Node) string) ([]string, error) {
+	n, err := s.Lookup(ctx, path)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, child := range n.Children {
+		if strings.HasPrefix(child.Name, partial) {
+			out = append(out, format(child))
+		}
+	}
+	return out, nil
+}
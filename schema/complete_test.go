@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestComplete(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` ``}]")
+		for _, name := range []string{"interface", "ip", "ipv6", "system"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+name, "=node-type=dir")
+		}
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=ip", "=node-type=dir")
+		for _, name := range []string{"address", "arp", "route"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+name, "=node-type=dir")
+		}
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address,add`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=add", "=node-type=cmd")
+		for _, name := range []string{"address", "comment", "interface"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+name, "=node-type=arg")
+		}
+		s.WriteSentence(t, "!done")
+	})
+
+	sc := New(c, Options{})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		line string
+		want []string
+	}{
+		{"/i", []string{"/interface", "/ip", "/ipv6"}},
+		{"/ip/a", []string{"/ip/address", "/ip/arp"}},
+		{"/ip/", []string{"/ip/address", "/ip/arp", "/ip/route"}},
+		{"/ip/x", nil},
+		{"/ip/address/add =a", []string{"=address="}},
+		{"/ip/address/add =address=10.0.0.1/24 ", []string{"=address=", "=comment=", "=interface="}},
+		{"/ip/address/add =address=10", nil},
+		{"/ip/address/add ?addr", nil},
+	} {
+		got, err := sc.Complete(ctx, tc.line)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got, tc.line)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Package schema discovers the menus, commands and arguments of a device with /console/inspect,
+available on RouterOS 7, to check commands before they are sent and to complete them in tools.
+
+The command tree is fetched lazily, one menu or command at a time, and cached in the Schema.
+/console/inspect does not tell which arguments are required, so those come from
+DefaultRequired and Options.Required.
+*/
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+var (
+	// ErrNoInspect is returned when the device has no /console/inspect, as on RouterOS 6.
+	ErrNoInspect = errors.New("device cannot describe its menus")
+	// ErrNotFound is returned for paths the device does not have.
+	ErrNotFound = errors.New("no such menu or command")
+	// ErrUnknownCommand is returned by Validate for sentences that are no command of the device.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrUnknownArg is returned by Validate for arguments the command does not have.
+	ErrUnknownArg = errors.New("unknown argument")
+	// ErrMissingArg is returned by Validate for required arguments that are not given.
+	ErrMissingArg = errors.New("missing argument")
+)
+
+// DefaultRequired are the arguments required by common commands, by command path.
+var DefaultRequired = map[string][]string{
+	"/certificate/add":                {"name", "common-name"},
+	"/interface/bonding/add":          {"slaves"},
+	"/interface/bridge/port/add":      {"bridge", "interface"},
+	"/interface/list/member/add":      {"list", "interface"},
+	"/interface/vlan/add":             {"interface", "vlan-id"},
+	"/ip/address/add":                 {"address", "interface"},
+	"/ip/dhcp-client/add":             {"interface"},
+	"/ip/dhcp-server/add":             {"interface"},
+	"/ip/firewall/address-list/add":   {"list", "address"},
+	"/ip/firewall/filter/add":         {"chain"},
+	"/ip/firewall/mangle/add":         {"chain"},
+	"/ip/firewall/nat/add":            {"chain"},
+	"/ip/firewall/raw/add":            {"chain"},
+	"/ip/pool/add":                    {"ranges"},
+	"/ipv6/firewall/address-list/add": {"list", "address"},
+	"/ipv6/firewall/filter/add":       {"chain"},
+	"/system/script/run":              {"number"},
+	"/user/add":                       {"name", "group"},
+}
+
+// NodeType is the kind of a node of the command tree.
+type NodeType string
+
+const (
+	// Dir is a menu, such as /ip/address.
+	Dir NodeType = "dir"
+	// Cmd is a command, such as /ip/address/add.
+	Cmd NodeType = "cmd"
+	// Arg is an argument of a command, such as address for /ip/address/add.
+	Arg NodeType = "arg"
+)
+
+// Node is a menu, command or argument.
+type Node struct {
+	// Path is the path of menus and commands in the form of the API, e.g. "/ip/address/add".
+	// Arguments have the path of their command followed by their name.
+	Path string
+	Name string
+	Type NodeType
+	// Children are the sub-menus and commands of a menu, or the arguments of a command,
+	// sorted by name.
+	Children []*Node
+}
+
+// Child returns the child called name, or nil.
+func (n *Node) Child(name string) *Node {
+	i := sort.Search(len(n.Children), func(i int) bool { return n.Children[i].Name >= name })
+	if i < len(n.Children) && n.Children[i].Name == name {
+		return n.Children[i]
+	}
+	return nil
+}
+
+// Options configure a Schema.
+type Options struct {
+	// Required overrides DefaultRequired for the commands it lists, by command path.
+	Required map[string][]string
+}
+
+// Schema is the command tree of a device. It is safe for concurrent use.
+type Schema struct {
+	c    *routeros.Client
+	opts Options
+
+	mu    sync.Mutex
+	nodes map[string]*Node
+	// noInspect is set once the device turned out to have no /console/inspect
+	noInspect bool
+}
+
+// New returns a Schema fetching the tree from c.
+func New(c *routeros.Client, opts Options) *Schema {
+	return &Schema{c: c, opts: opts, nodes: make(map[string]*Node)}
+}
+
+// Lookup returns the node at path with its children, e.g. "/ip/address" or "/ip/address/add".
+// It fails with ErrNotFound if the device has no such menu or command.
+func (s *Schema) Lookup(ctx context.Context, path string) (*Node, error) {
+	path = "/" + strings.Trim(path, "/")
+
+	s.mu.Lock()
+	n, ok := s.nodes[path]
+	noInspect := s.noInspect
+	s.mu.Unlock()
+	if !ok {
+		if noInspect {
+			return nil, ErrNoInspect
+		}
+
+		var err error
+		if n, err = s.inspect(ctx, path); err != nil {
+			if errors.Is(err, ErrNoInspect) {
+				s.mu.Lock()
+				s.noInspect = true
+				s.mu.Unlock()
+			}
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.nodes[path] = n
+		s.mu.Unlock()
+	}
+
+	if n == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	return n, nil
+}
+
+// inspect fetches the node at path, or nil if it does not exist.
+func (s *Schema) inspect(ctx context.Context, path string) (*Node, error) {
+	r, err := s.c.RunContext(ctx, "/console/inspect", "=request=child",
+		"=path="+strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", ","))
+	if err != nil {
+		var devErr *routeros.DeviceError
+		if errors.As(err, &devErr) && strings.Contains(devErr.Sentence.Map["message"], "no such command") {
+			return nil, ErrNoInspect
+		}
+		return nil, err
+	}
+
+	n := &Node{Path: path}
+	if path == "/" {
+		n.Type = Dir
+	}
+	for _, sen := range r.Re {
+		switch sen.Map["type"] {
+		case "self":
+			n.Name, n.Type = sen.Map["name"], NodeType(sen.Map["node-type"])
+		case "child":
+			name := sen.Map["name"]
+			n.Children = append(n.Children, &Node{
+				Path: strings.TrimSuffix(path, "/") + "/" + name,
+				Name: name,
+				Type: NodeType(sen.Map["node-type"]),
+			})
+		}
+	}
+	if n.Type == "" {
+		return nil, nil
+	}
+
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	return n, nil
+}
+
+// Required returns the arguments required by the command at path.
+func (s *Schema) Required(path string) []string {
+	if args, ok := s.opts.Required[path]; ok {
+		return args
+	}
+	return DefaultRequired[path]
+}
+
+// Validate checks that sentence is a command of the device, that its arguments are known to
+// the command and that the required ones are given. API attributes such as =.proplist= and
+// =.id=, and queries, are not checked. The errors found are joined.
+func (s *Schema) Validate(ctx context.Context, sentence []string) error {
+	if len(sentence) == 0 {
+		return fmt.Errorf("%w: empty sentence", ErrUnknownCommand)
+	}
+
+	cmd, err := s.Lookup(ctx, sentence[0])
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, sentence[0])
+	}
+	if err != nil {
+		return err
+	}
+	path := cmd.Path
+	if cmd.Type != Cmd {
+		return fmt.Errorf("%w: %s is a menu", ErrUnknownCommand, path)
+	}
+
+	var errs []error
+	given := make(map[string]bool)
+	for _, word := range sentence[1:] {
+		if !strings.HasPrefix(word, "=") {
+			continue
+		}
+		name, _, _ := strings.Cut(word[1:], "=")
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		given[name] = true
+		if cmd.Child(name) == nil {
+			errs = append(errs, fmt.Errorf("%w: %s %s", ErrUnknownArg, path, name))
+		}
+	}
+	for _, name := range s.Required(path) {
+		if !given[name] {
+			errs = append(errs, fmt.Errorf("%w: %s %s", ErrMissingArg, path, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunArgs validates sentence and runs it if it is valid. On devices without /console/inspect,
+// such as RouterOS 6, the sentence is run without validation.
+func (s *Schema) RunArgs(ctx context.Context, sentence []string) (*routeros.Reply, error) {
+	if err := s.Validate(ctx, sentence); err != nil && !errors.Is(err, ErrNoInspect) {
+		return nil, err
+	}
+	return s.c.RunArgsContext(ctx, sentence)
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestLookup(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=address", "=node-type=dir")
+		s.WriteSentence(t, "!re", "=type=child", "=name=print", "=node-type=cmd")
+		s.WriteSentence(t, "!re", "=type=child", "=name=add", "=node-type=cmd")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,adress`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	sc := New(c, Options{})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		// the second lookup is answered from the cache
+		n, err := sc.Lookup(ctx, "/ip/address")
+		require.NoError(t, err)
+		require.Equal(t, Dir, n.Type)
+		require.Len(t, n.Children, 2)
+		require.Equal(t, "add", n.Children[0].Name)
+		require.Equal(t, &Node{Path: "/ip/address/print", Name: "print", Type: Cmd}, n.Child("print"))
+		require.Nil(t, n.Child("bogus"))
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := sc.Lookup(ctx, "ip/adress/")
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+}
+
+func TestLookupNoInspect(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip`}]")
+		s.WriteSentence(t, "!trap", "=message=no such command prefix")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c, Options{}).Lookup(context.Background(), "/ip")
+	require.ErrorIs(t, err, ErrNoInspect)
+}
+
+func TestRunArgsNoInspect(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `system,identity,print`}]")
+		s.WriteSentence(t, "!trap", "=message=no such command prefix")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/identity/print @ []")
+		s.WriteSentence(t, "!re", "=name=MikroTik")
+		s.WriteSentence(t, "!done")
+		// the missing inspect is remembered
+		s.ReadSentence(t, "/system/identity/print @ []")
+		s.WriteSentence(t, "!re", "=name=MikroTik")
+		s.WriteSentence(t, "!done")
+	})
+
+	sc := New(c, Options{})
+	for i := 0; i < 2; i++ {
+		r, err := sc.RunArgs(context.Background(), []string{"/system/identity/print"})
+		require.NoError(t, err)
+		require.Equal(t, "MikroTik", r.Re[0].Map["name"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address,add`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=add", "=node-type=cmd")
+		for _, arg := range []string{"address", "comment", "disabled", "interface", "network"} {
+			s.WriteSentence(t, "!re", "=type=child", "=name="+arg, "=node-type=arg")
+		}
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,address`}]")
+		s.WriteSentence(t, "!re", "=type=self", "=name=address", "=node-type=dir")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/console/inspect @ [{`request` `child`} {`path` `ip,adress,add`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/address/add @ [{`address` `10.0.0.1/24`} {`interface` `ether1`}]")
+		s.WriteSentence(t, "!done", "=ret=*1")
+	})
+
+	sc := New(c, Options{})
+	ctx := context.Background()
+
+	require.NoError(t, sc.Validate(ctx, []string{"/ip/address/add", "=address=10.0.0.1/24", "=interface=ether1", "=.tag=x"}))
+
+	err := sc.Validate(ctx, []string{"/ip/address/add", "=address=10.0.0.1/24", "=iface=ether1"})
+	require.ErrorIs(t, err, ErrUnknownArg)
+	require.ErrorIs(t, err, ErrMissingArg)
+	require.EqualError(t, err, "unknown argument: /ip/address/add iface\nmissing argument: /ip/address/add interface")
+
+	err = sc.Validate(ctx, []string{"/ip/address", "=address=10.0.0.1/24"})
+	require.EqualError(t, err, "unknown command: /ip/address is a menu")
+
+	err = sc.Validate(ctx, []string{"/ip/adress/add"})
+	require.ErrorIs(t, err, ErrUnknownCommand)
+
+	// the device is not asked for commands that are not valid
+	_, err = sc.RunArgs(ctx, []string{"/ip/address/add", "=address=10.0.0.1/24"})
+	require.ErrorIs(t, err, ErrMissingArg)
+
+	r, err := sc.RunArgs(ctx, []string{"/ip/address/add", "=address=10.0.0.1/24", "=interface=ether1"})
+	require.NoError(t, err)
+	require.Equal(t, "*1", r.Done.Map["ret"])
+}
+
+func TestRequired(t *testing.T) {
+	sc := New(nil, Options{Required: map[string][]string{
+		"/ip/address/add": nil,
+		"/ip/route/add":   {"gateway"},
+	}})
+
+	require.Empty(t, sc.Required("/ip/address/add"))
+	require.Equal(t, []string{"gateway"}, sc.Required("/ip/route/add"))
+	require.Equal(t, []string{"chain"}, sc.Required("/ip/firewall/filter/add"))
+}
@@ -0,0 +1,228 @@
+/*
+Package scripts manages and runs RouterOS scripts: the named scripts of /system/script, and
+ad-hoc code run with /execute.
+
+Errors reported by the script engine (syntax errors, failed commands, ...) are returned as
+*ScriptError, with the position of the error when the device tells it.
+*/
+package scripts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/attrs"
+	"github.com/go-routeros/routeros/v3/proto"
+	"github.com/go-routeros/routeros/v3/value"
+)
+
+const scriptPath = "/system/script"
+
+var ErrNotFound = errors.New("script not found")
+
+// Script is an entry of /system/script.
+type Script struct {
+	ID      string
+	Name    string
+	Owner   string
+	Comment string
+	Source  string
+	// Policy lists the permissions of the script, e.g. read, write, test.
+	Policy                 []string
+	DontRequirePermissions bool
+
+	// The fields below are set by the device and ignored by Put.
+	RunCount    int64
+	LastStarted string
+	// Invalid tells that the source does not parse.
+	Invalid bool
+}
+
+// Client manages scripts through a routeros.Client.
+type Client struct {
+	c *routeros.Client
+}
+
+// New returns a Client using c.
+func New(c *routeros.Client) *Client {
+	return &Client{c: c}
+}
+
+// List returns all the scripts.
+func (c *Client) List(ctx context.Context) ([]Script, error) {
+	return c.list(ctx)
+}
+
+// Get returns the script called name.
+func (c *Client) Get(ctx context.Context, name string) (*Script, error) {
+	list, err := c.list(ctx, "?name="+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return &list[0], nil
+}
+
+func (c *Client) list(ctx context.Context, query ...string) ([]Script, error) {
+	r, err := c.c.RunArgsContext(ctx, append([]string{scriptPath + "/print"}, query...))
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make([]Script, 0, len(r.Re))
+	for _, sen := range r.Re {
+		s, err := parseScript(sen)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, s)
+	}
+	return scripts, nil
+}
+
+// Put uploads s, adding it if there is no script with the same name and replacing the
+// source, policy and comment of the existing one otherwise.
+func (c *Client) Put(ctx context.Context, s Script) error {
+	if s.Name == "" {
+		return errors.New("script without name")
+	}
+
+	args := []string{
+		"=source=" + s.Source,
+		"=comment=" + s.Comment,
+		"=dont-require-permissions=" + value.FormatBool(s.DontRequirePermissions),
+	}
+	if len(s.Policy) > 0 {
+		args = append(args, "=policy="+value.FormatList(s.Policy))
+	}
+
+	_, err := c.Get(ctx, s.Name)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		_, err = c.c.RunArgsContext(ctx, append([]string{scriptPath + "/add", "=name=" + s.Name}, args...))
+	case err == nil:
+		_, err = c.c.RunArgsContext(ctx, append([]string{scriptPath + "/set", "=numbers=" + s.Name}, args...))
+	}
+	return err
+}
+
+// Remove removes the scripts called names.
+func (c *Client) Remove(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := c.c.RunContext(ctx, scriptPath+"/remove", "=numbers="+strings.Join(names, ","))
+	return err
+}
+
+// Run runs the script called name and waits for it to end. It returns what the device answers,
+// which is empty unless the script ends with :return on recent RouterOS versions; use Execute
+// to capture the output of :put.
+func (c *Client) Run(ctx context.Context, name string) (string, error) {
+	r, err := c.c.RunContext(ctx, scriptPath+"/run", "=number="+name)
+	if err != nil {
+		return "", scriptError(name, err)
+	}
+
+	// Done is nil if the client was closed while the script ran
+	if r.Done == nil {
+		return "", fmt.Errorf("run %s: %w", name, io.ErrUnexpectedEOF)
+	}
+	return r.Done.Map["ret"], nil
+}
+
+// Execute runs code with /execute and returns its output, as collected by as-string.
+func (c *Client) Execute(ctx context.Context, code string) (string, error) {
+	r, err := c.c.RunContext(ctx, "/execute", "=script="+code, "=as-string=")
+	if err != nil {
+		return "", scriptError("", err)
+	}
+
+	if r.Done == nil {
+		return "", fmt.Errorf("execute: %w", io.ErrUnexpectedEOF)
+	}
+	return r.Done.Map["ret"], nil
+}
+
+// ScriptError is an error reported by the script engine of the device.
+type ScriptError struct {
+	// Script is the name of the script, empty for code run with Execute.
+	Script string
+	// Message is the message sent by the device, without the position.
+	Message string
+	// Line and Column are the position of the error in the source, zero if unknown.
+	Line, Column int
+
+	Err *routeros.DeviceError
+}
+
+func (err *ScriptError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("script")
+	if err.Script != "" {
+		sb.WriteString(" " + err.Script)
+	}
+	if err.Line > 0 {
+		fmt.Fprintf(&sb, " (line %d column %d)", err.Line, err.Column)
+	}
+	sb.WriteString(": " + err.Message)
+	return sb.String()
+}
+
+func (err *ScriptError) Unwrap() error {
+	return err.Err
+}
+
+// position matches the location the device appends to script errors, e.g. "(line 3 column 7)".
+var position = regexp.MustCompile(`\s*\(line (\d+) column (\d+)\)`)
+
+// scriptError turns the !trap replies of the script engine into *ScriptError. Other errors
+// (I/O errors, !fatal) are returned as they are.
+func scriptError(name string, err error) error {
+	var devErr *routeros.DeviceError
+	if !errors.As(err, &devErr) || devErr.Sentence.Word != "!trap" {
+		return err
+	}
+
+	msg := devErr.Sentence.Map["message"]
+	msg = strings.TrimPrefix(msg, "failure: ")
+	msg = strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n"))
+
+	se := &ScriptError{Script: name, Err: devErr}
+	if m := position.FindStringSubmatchIndex(msg); m != nil {
+		se.Line, _ = strconv.Atoi(msg[m[2]:m[3]])
+		se.Column, _ = strconv.Atoi(msg[m[4]:m[5]])
+		msg = msg[:m[0]] + msg[m[1]:]
+	}
+	se.Message = strings.TrimSpace(msg)
+	return se
+}
+
+func parseScript(sen *proto.Sentence) (Script, error) {
+	p := attrs.New(sen.Map)
+	s := Script{
+		ID:                     sen.ID(),
+		Name:                   p.String("name"),
+		Owner:                  p.String("owner"),
+		Comment:                p.String("comment"),
+		Source:                 p.String("source"),
+		Policy:                 p.List("policy"),
+		DontRequirePermissions: p.Bool("dont-require-permissions"),
+		RunCount:               p.Int("run-count"),
+		LastStarted:            p.String("last-started"),
+		Invalid:                p.Bool("invalid"),
+	}
+	if err := p.Err(); err != nil {
+		return Script{}, fmt.Errorf("script %s: %w", s.Name, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,160 @@
+package scripts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestList(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/print @ []")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=backup", "=owner=admin", "=policy=ftp,read,write,policy,test",
+			"=dont-require-permissions=false", "=run-count=3", "=last-started=2024-01-02 03:04:05", "=source=/system backup save", "=invalid=false")
+		s.WriteSentence(t, "!done")
+	})
+
+	list, err := New(c).List(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Script{{
+		ID:          "*1",
+		Name:        "backup",
+		Owner:       "admin",
+		Source:      "/system backup save",
+		Policy:      []string{"ftp", "read", "write", "policy", "test"},
+		RunCount:    3,
+		LastStarted: "2024-01-02 03:04:05",
+	}}, list)
+}
+
+func TestGetNotFound(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/print @ [] ?[`name=missing`]")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).Get(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPut(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/print @ [] ?[`name=hello`]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/script/add @ [{`name` `hello`} {`source` `:put hello`} {`comment` ``} "+
+			"{`dont-require-permissions` `no`} {`policy` `read,test`}]")
+		s.WriteSentence(t, "!done", "=ret=*2")
+
+		s.ReadSentence(t, "/system/script/print @ [] ?[`name=hello`]")
+		s.WriteSentence(t, "!re", "=.id=*2", "=name=hello", "=source=:put hello")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/script/set @ [{`numbers` `hello`} {`source` `:put world`} {`comment` `v2`} "+
+			"{`dont-require-permissions` `yes`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	sc := New(c)
+	require.NoError(t, sc.Put(context.Background(), Script{Name: "hello", Source: ":put hello", Policy: []string{"read", "test"}}))
+	require.NoError(t, sc.Put(context.Background(), Script{Name: "hello", Source: ":put world", Comment: "v2", DontRequirePermissions: true}))
+}
+
+func TestRemove(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/remove @ [{`numbers` `a,b`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	require.NoError(t, New(c).Remove(context.Background(), "a", "b"))
+}
+
+func TestRun(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/run @ [{`number` `hello`}]")
+		s.WriteSentence(t, "!done", "=ret=42")
+	})
+
+	out, err := New(c).Run(context.Background(), "hello")
+	require.NoError(t, err)
+	require.Equal(t, "42", out)
+}
+
+func TestRunClosed(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	c.Async()
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/run @r1 [{`number` `hello`}]")
+		// let the write of the command return before closing
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, c.Close())
+	})
+
+	_, err := New(c).Run(context.Background(), "hello")
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestRunFailure(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/script/run @ [{`number` `broken`}]")
+		s.WriteSentence(t, "!trap", "=message=failure: expected end of command (line 2 column 7)")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).Run(context.Background(), "broken")
+
+	var se *ScriptError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, "broken", se.Script)
+	require.Equal(t, "expected end of command", se.Message)
+	require.Equal(t, 2, se.Line)
+	require.Equal(t, 7, se.Column)
+	require.EqualError(t, err, "script broken (line 2 column 7): expected end of command")
+
+	var devErr *routeros.DeviceError
+	require.True(t, errors.As(err, &devErr))
+}
+
+func TestExecute(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/execute @ [{`script` `:put [/system identity get name]`} {`as-string` ``}]")
+		s.WriteSentence(t, "!done", "=ret=MikroTik\r\n")
+	})
+
+	out, err := New(c).Execute(context.Background(), ":put [/system identity get name]")
+	require.NoError(t, err)
+	require.Equal(t, "MikroTik\r\n", out)
+}
+
+func TestExecuteFailure(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/execute @ [{`script` `:error oops`} {`as-string` ``}]")
+		s.WriteSentence(t, "!trap", "=message=oops")
+		s.WriteSentence(t, "!done")
+	})
+
+	_, err := New(c).Execute(context.Background(), ":error oops")
+	require.EqualError(t, err, "script: oops")
+}
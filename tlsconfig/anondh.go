@@ -0,0 +1,696 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the ADH-AES*-SHA suites
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrAnonymousDHHandshake is returned when the anonymous Diffie-Hellman handshake fails on the
+// side of the client, e.g. because the device sent an invalid message.
+var ErrAnonymousDHHandshake = errors.New("anonymous DH handshake failed")
+
+// minDHBits is the smallest Diffie-Hellman group accepted from the device.
+const minDHBits = 1024
+
+const (
+	recordChangeCipherSpec = 20
+	recordAlert            = 21
+	recordHandshake        = 22
+	recordApplicationData  = 23
+
+	typeClientHello       = 1
+	typeServerHello       = 2
+	typeServerKeyExchange = 12
+	typeServerHelloDone   = 14
+	typeClientKeyExchange = 16
+	typeFinished          = 20
+
+	maxPlaintext = 1 << 14
+	// maxCiphertext bounds the records read, as in RFC 5246, section 6.2.3
+	maxCiphertext = maxPlaintext + 2048
+)
+
+// anonSuite is a TLS 1.2 anonymous Diffie-Hellman cipher suite, with AES in GCM or CBC mode.
+type anonSuite struct {
+	id     uint16
+	keyLen int
+	// mac is nil for GCM suites
+	mac    func() hash.Hash
+	macLen int
+	prf    func() hash.Hash
+}
+
+// anonSuites are offered in this order of preference.
+var anonSuites = []anonSuite{
+	{id: 0x00a7, keyLen: 32, prf: sha512.New384},                           // ADH-AES256-GCM-SHA384
+	{id: 0x00a6, keyLen: 16, prf: sha256.New},                              // ADH-AES128-GCM-SHA256
+	{id: 0x006d, keyLen: 32, mac: sha256.New, macLen: 32, prf: sha256.New}, // ADH-AES256-SHA256
+	{id: 0x006c, keyLen: 16, mac: sha256.New, macLen: 32, prf: sha256.New}, // ADH-AES128-SHA256
+	{id: 0x003a, keyLen: 32, mac: sha1.New, macLen: 20, prf: sha256.New},   // ADH-AES256-SHA
+	{id: 0x0034, keyLen: 16, mac: sha1.New, macLen: 20, prf: sha256.New},   // ADH-AES128-SHA
+}
+
+// DialAnonymousDH connects to address and runs an anonymous Diffie-Hellman handshake with
+// AnonymousDHClient. It fits routeros.Dialer.DialConn.
+func DialAnonymousDH(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := new(net.Dialer).DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := AnonymousDHClient(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// AnonymousDHFallback returns a function for routeros.Dialer.DialConn connecting with TLS and
+// cfg, and with DialAnonymousDH instead if the device refuses the handshake with the
+// handshake_failure alert of an api-ssl service without certificate.
+//
+// Anyone able to tamper with the connection can make the device refuse the first handshake, and
+// the anonymous one authenticates nothing, so the fallback defeats pinning. Only use it for
+// devices that cannot have a certificate.
+func AnonymousDHFallback(cfg *tls.Config) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := (&tls.Dialer{Config: cfg}).DialContext(ctx, network, address)
+		if err == nil || !isHandshakeFailure(err) {
+			return conn, err
+		}
+		return DialAnonymousDH(ctx, network, address)
+	}
+}
+
+// AnonymousDHClient runs a TLS 1.2 handshake with the anonymous Diffie-Hellman cipher suites
+// that the api-ssl service of RouterOS offers when it has no certificate, which crypto/tls does
+// not implement, and returns the encrypted connection. conn is not closed on error.
+//
+// The device is not authenticated: the connection is safe from eavesdropping, but not from
+// anyone able to tamper with it.
+func AnonymousDHClient(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	if dl, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(dl); err != nil {
+			return nil, err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	c := &anonConn{Conn: conn}
+	err := c.handshake()
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// anonConn is a TLS 1.2 connection with an anonymous Diffie-Hellman cipher suite. As with
+// tls.Conn, a timed out Read can be retried, but a timed out Write breaks the connection.
+type anonConn struct {
+	net.Conn
+	suite *anonSuite
+
+	rmu sync.Mutex
+	in  halfConn
+	// raw holds what is read of the next record, input what is left of the last one
+	raw   bytes.Buffer
+	input []byte
+	rerr  error
+
+	wmu  sync.Mutex
+	out  halfConn
+	werr error
+
+	// handshake state
+	hs         []byte
+	transcript []byte
+}
+
+// ConnectionState returns the version and cipher suite of the connection. It also lets the
+// proto package handle write timeouts as for tls.Conn.
+func (c *anonConn) ConnectionState() tls.ConnectionState {
+	return tls.ConnectionState{
+		Version:           tls.VersionTLS12,
+		HandshakeComplete: true,
+		CipherSuite:       c.suite.id,
+	}
+}
+
+func (c *anonConn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for len(c.input) == 0 {
+		if c.rerr != nil {
+			return 0, c.rerr
+		}
+
+		typ, data, err := c.readRecord()
+		if err != nil {
+			return 0, err
+		}
+
+		switch typ {
+		case recordApplicationData:
+			c.input = data
+		case recordAlert:
+			if err := alertErr(data); err != nil {
+				c.rerr = err
+			}
+		case recordHandshake:
+			// a renegotiation request, which may be ignored
+		default:
+			c.rerr = fmt.Errorf("%w: unexpected record type %d", ErrAnonymousDHHandshake, typ)
+		}
+	}
+
+	n := copy(p, c.input)
+	c.input = c.input[n:]
+	return n, nil
+}
+
+func (c *anonConn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	var n int
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxPlaintext)]
+		if err := c.writeRecord(recordApplicationData, chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+// writeRecord must be called with wmu held.
+func (c *anonConn) writeRecord(typ byte, data []byte) error {
+	if c.werr != nil {
+		return c.werr
+	}
+
+	version := uint16(tls.VersionTLS12)
+	if typ == recordHandshake && data[0] == typeClientHello {
+		// as other clients do, for old servers
+		version = tls.VersionTLS10
+	}
+
+	if _, err := c.Conn.Write(c.out.seal(typ, version, data)); err != nil {
+		// part of the record may have been written
+		c.werr = err
+		return err
+	}
+	return nil
+}
+
+// readRecord returns the type and the decrypted content of the next record. Reads cut by a
+// deadline keep what they read for the next call. It must be called with rmu held.
+func (c *anonConn) readRecord() (byte, []byte, error) {
+	var buf [4096]byte
+	for {
+		if b := c.raw.Bytes(); len(b) >= 5 {
+			n := int(binary.BigEndian.Uint16(b[3:5]))
+			if n > maxCiphertext {
+				c.rerr = fmt.Errorf("%w: record too large", ErrAnonymousDHHandshake)
+				return 0, nil, c.rerr
+			}
+			if len(b) >= 5+n {
+				header := c.raw.Next(5)
+				typ, version := header[0], binary.BigEndian.Uint16(header[1:3])
+				data, err := c.in.open(typ, version, c.raw.Next(n))
+				if err != nil {
+					c.rerr = err
+					return 0, nil, err
+				}
+				return typ, data, nil
+			}
+		}
+
+		n, err := c.Conn.Read(buf[:])
+		c.raw.Write(buf[:n])
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+				if c.raw.Len() == 0 {
+					err = io.EOF
+				}
+			}
+			return 0, nil, err
+		}
+	}
+}
+
+func (c *anonConn) handshake() error {
+	clientRandom := make([]byte, 32)
+	if _, err := rand.Read(clientRandom); err != nil {
+		return err
+	}
+
+	if err := c.writeHandshake(clientHello(clientRandom)); err != nil {
+		return err
+	}
+
+	msg, err := c.readHandshake(typeServerHello)
+	if err != nil {
+		return err
+	}
+	serverRandom, err := c.parseServerHello(msg)
+	if err != nil {
+		return err
+	}
+
+	if msg, err = c.readHandshake(typeServerKeyExchange); err != nil {
+		return err
+	}
+	p, g, ys, err := parseServerKeyExchange(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.readHandshake(typeServerHelloDone); err != nil {
+		return err
+	}
+
+	// x in [2, p-2]
+	x, err := rand.Int(rand.Reader, new(big.Int).Sub(p, big.NewInt(3)))
+	if err != nil {
+		return err
+	}
+	x.Add(x, big.NewInt(2))
+	yc := new(big.Int).Exp(g, x, p).Bytes()
+	preMaster := new(big.Int).Exp(ys, x, p).Bytes()
+
+	if err := c.writeHandshake(handshakeMsg(typeClientKeyExchange, lengthPrefixed(yc))); err != nil {
+		return err
+	}
+
+	master := prf(c.suite.prf, preMaster, "master secret", concat(clientRandom, serverRandom), 48)
+	clientKeys, serverKeys, err := c.suite.keys(master, clientRandom, serverRandom)
+	if err != nil {
+		return err
+	}
+
+	if err := c.writeRecord(recordChangeCipherSpec, []byte{1}); err != nil {
+		return err
+	}
+	c.out = clientKeys
+	if err := c.writeHandshake(handshakeMsg(typeFinished, c.verifyData(master, "client finished"))); err != nil {
+		return err
+	}
+
+	typ, data, err := c.readRecord()
+	if err != nil {
+		return err
+	}
+	if typ == recordAlert {
+		return alertErr(data)
+	}
+	if typ != recordChangeCipherSpec || !bytes.Equal(data, []byte{1}) {
+		return fmt.Errorf("%w: expected ChangeCipherSpec", ErrAnonymousDHHandshake)
+	}
+	c.in = serverKeys
+
+	want := c.verifyData(master, "server finished")
+	if msg, err = c.readHandshake(typeFinished); err != nil {
+		return err
+	}
+	if !hmac.Equal(msg[4:], want) {
+		return fmt.Errorf("%w: wrong Finished message", ErrAnonymousDHHandshake)
+	}
+
+	c.transcript = nil
+	return nil
+}
+
+func (c *anonConn) writeHandshake(msg []byte) error {
+	c.transcript = append(c.transcript, msg...)
+	return c.writeRecord(recordHandshake, msg)
+}
+
+// readHandshake returns the next handshake message, which must be of type typ.
+func (c *anonConn) readHandshake(typ byte) ([]byte, error) {
+	for len(c.hs) < 4 || len(c.hs) < 4+int(uint32(c.hs[1])<<16|uint32(c.hs[2])<<8|uint32(c.hs[3])) {
+		if len(c.hs) >= 4 && c.hs[1] != 0 {
+			return nil, fmt.Errorf("%w: handshake message too large", ErrAnonymousDHHandshake)
+		}
+
+		rtyp, data, err := c.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		switch rtyp {
+		case recordHandshake:
+			c.hs = append(c.hs, data...)
+		case recordAlert:
+			if err := alertErr(data); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected record type %d", ErrAnonymousDHHandshake, rtyp)
+		}
+	}
+
+	n := 4 + int(uint32(c.hs[2])<<8|uint32(c.hs[3]))
+	msg := c.hs[:n:n]
+	c.hs = c.hs[n:]
+	if msg[0] != typ {
+		return nil, fmt.Errorf("%w: expected handshake message %d, got %d", ErrAnonymousDHHandshake, typ, msg[0])
+	}
+
+	c.transcript = append(c.transcript, msg...)
+	return msg, nil
+}
+
+func (c *anonConn) parseServerHello(msg []byte) ([]byte, error) {
+	body := msg[4:]
+	if len(body) < 2+32+1 {
+		return nil, fmt.Errorf("%w: short ServerHello", ErrAnonymousDHHandshake)
+	}
+	if v := binary.BigEndian.Uint16(body); v != tls.VersionTLS12 {
+		return nil, fmt.Errorf("%w: the device chose TLS version %#04x, only TLS 1.2 is supported", ErrAnonymousDHHandshake, v)
+	}
+	serverRandom := body[2:34]
+
+	rest := body[34:]
+	if len(rest) < 1+int(rest[0])+3 {
+		return nil, fmt.Errorf("%w: short ServerHello", ErrAnonymousDHHandshake)
+	}
+	rest = rest[1+int(rest[0]):]
+
+	id := binary.BigEndian.Uint16(rest)
+	for i := range anonSuites {
+		if anonSuites[i].id == id {
+			c.suite = &anonSuites[i]
+		}
+	}
+	if c.suite == nil {
+		return nil, fmt.Errorf("%w: the device chose cipher suite %#04x, which was not offered", ErrAnonymousDHHandshake, id)
+	}
+	if rest[2] != 0 {
+		return nil, fmt.Errorf("%w: the device chose compression", ErrAnonymousDHHandshake)
+	}
+	return serverRandom, nil
+}
+
+// parseServerKeyExchange returns the Diffie-Hellman group and the public value of the device.
+func parseServerKeyExchange(msg []byte) (p, g, ys *big.Int, err error) {
+	body := msg[4:]
+	var params [3]*big.Int
+	for i := range params {
+		if len(body) < 2 || len(body) < 2+int(binary.BigEndian.Uint16(body)) {
+			return nil, nil, nil, fmt.Errorf("%w: short ServerKeyExchange", ErrAnonymousDHHandshake)
+		}
+		n := int(binary.BigEndian.Uint16(body))
+		params[i] = new(big.Int).SetBytes(body[2 : 2+n])
+		body = body[2+n:]
+	}
+	p, g, ys = params[0], params[1], params[2]
+
+	if p.BitLen() < minDHBits {
+		return nil, nil, nil, fmt.Errorf("%w: %d-bit Diffie-Hellman group, at least %d required", ErrAnonymousDHHandshake, p.BitLen(), minDHBits)
+	}
+	pMinus1 := new(big.Int).Sub(p, big.NewInt(1))
+	one := big.NewInt(1)
+	if g.Cmp(one) <= 0 || g.Cmp(pMinus1) >= 0 || ys.Cmp(one) <= 0 || ys.Cmp(pMinus1) >= 0 {
+		return nil, nil, nil, fmt.Errorf("%w: invalid Diffie-Hellman parameters", ErrAnonymousDHHandshake)
+	}
+	return p, g, ys, nil
+}
+
+func (c *anonConn) verifyData(master []byte, label string) []byte {
+	h := c.suite.prf()
+	h.Write(c.transcript)
+	return prf(c.suite.prf, master, label, h.Sum(nil), 12)
+}
+
+// keys derives the record protection of both sides from the master secret.
+func (s *anonSuite) keys(master, clientRandom, serverRandom []byte) (client, server halfConn, err error) {
+	ivLen := 0
+	if s.mac == nil {
+		ivLen = 4
+	}
+
+	kb := prf(s.prf, master, "key expansion", concat(serverRandom, clientRandom), 2*(s.macLen+s.keyLen+ivLen))
+	next := func(n int) []byte {
+		b := kb[:n]
+		kb = kb[n:]
+		return b
+	}
+	clientMAC, serverMAC := next(s.macLen), next(s.macLen)
+	clientKey, serverKey := next(s.keyLen), next(s.keyLen)
+	clientIV, serverIV := next(ivLen), next(ivLen)
+
+	if client, err = s.halfConn(clientMAC, clientKey, clientIV); err != nil {
+		return halfConn{}, halfConn{}, err
+	}
+	if server, err = s.halfConn(serverMAC, serverKey, serverIV); err != nil {
+		return halfConn{}, halfConn{}, err
+	}
+	return client, server, nil
+}
+
+func (s *anonSuite) halfConn(macKey, key, iv []byte) (halfConn, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return halfConn{}, err
+	}
+	if s.mac != nil {
+		return halfConn{block: block, mac: hmac.New(s.mac, macKey)}, nil
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return halfConn{}, err
+	}
+	return halfConn{aead: aead, fixedIV: iv}, nil
+}
+
+// halfConn protects the records of one direction. The zero value leaves them in plaintext, as
+// before ChangeCipherSpec.
+type halfConn struct {
+	seq uint64
+
+	// CBC suites
+	block cipher.Block
+	mac   hash.Hash
+
+	// GCM suites
+	aead    cipher.AEAD
+	fixedIV []byte
+}
+
+// additionalData returns what the MAC or the AEAD covers besides the content of a record.
+func (h *halfConn) additionalData(typ byte, version uint16, n int) []byte {
+	ad := make([]byte, 13)
+	binary.BigEndian.PutUint64(ad, h.seq)
+	ad[8] = typ
+	binary.BigEndian.PutUint16(ad[9:], version)
+	binary.BigEndian.PutUint16(ad[11:], uint16(n))
+	return ad
+}
+
+// seal returns the record of type typ holding data.
+func (h *halfConn) seal(typ byte, version uint16, data []byte) []byte {
+	record := []byte{typ, byte(version >> 8), byte(version), 0, 0}
+
+	switch {
+	case h.aead != nil:
+		explicit := binary.BigEndian.AppendUint64(nil, h.seq)
+		nonce := concat(h.fixedIV, explicit)
+		record = append(record, explicit...)
+		record = h.aead.Seal(record, nonce, data, h.additionalData(typ, version, len(data)))
+		h.seq++
+	case h.block != nil:
+		h.mac.Reset()
+		h.mac.Write(h.additionalData(typ, version, len(data)))
+		h.mac.Write(data)
+		plain := h.mac.Sum(concat(data))
+
+		bs := h.block.BlockSize()
+		pad := bs - len(plain)%bs
+		for i := 0; i < pad; i++ {
+			plain = append(plain, byte(pad-1))
+		}
+
+		iv := make([]byte, bs)
+		_, _ = rand.Read(iv)
+		record = append(record, iv...)
+		start := len(record)
+		record = append(record, plain...)
+		cipher.NewCBCEncrypter(h.block, iv).CryptBlocks(record[start:], record[start:])
+		h.seq++
+	default:
+		record = append(record, data...)
+	}
+
+	binary.BigEndian.PutUint16(record[3:], uint16(len(record)-5))
+	return record
+}
+
+var errBadRecordMAC = fmt.Errorf("%w: bad record MAC", ErrAnonymousDHHandshake)
+
+// open returns the content of a record.
+func (h *halfConn) open(typ byte, version uint16, payload []byte) ([]byte, error) {
+	switch {
+	case h.aead != nil:
+		if len(payload) < 8+h.aead.Overhead() {
+			return nil, errBadRecordMAC
+		}
+		nonce := concat(h.fixedIV, payload[:8])
+		ad := h.additionalData(typ, version, len(payload)-8-h.aead.Overhead())
+		data, err := h.aead.Open(nil, nonce, payload[8:], ad)
+		if err != nil {
+			return nil, errBadRecordMAC
+		}
+		h.seq++
+		return data, nil
+	case h.block != nil:
+		bs, macLen := h.block.BlockSize(), h.mac.Size()
+		if len(payload)%bs != 0 || len(payload) < bs+max(bs, macLen+1) {
+			return nil, errBadRecordMAC
+		}
+		plain := make([]byte, len(payload)-bs)
+		cipher.NewCBCDecrypter(h.block, payload[:bs]).CryptBlocks(plain, payload[bs:])
+
+		pad := int(plain[len(plain)-1])
+		if pad+1+macLen > len(plain) {
+			return nil, errBadRecordMAC
+		}
+		good := true
+		for _, b := range plain[len(plain)-1-pad:] {
+			good = good && int(b) == pad
+		}
+		data := plain[:len(plain)-1-pad-macLen]
+
+		h.mac.Reset()
+		h.mac.Write(h.additionalData(typ, version, len(data)))
+		h.mac.Write(data)
+		if !hmac.Equal(h.mac.Sum(nil), plain[len(data):len(data)+macLen]) || !good {
+			return nil, errBadRecordMAC
+		}
+		h.seq++
+		return data, nil
+	default:
+		return append([]byte(nil), payload...), nil
+	}
+}
+
+func clientHello(random []byte) []byte {
+	body := binary.BigEndian.AppendUint16(nil, tls.VersionTLS12)
+	body = append(body, random...)
+	body = append(body, 0) // no session id
+
+	body = binary.BigEndian.AppendUint16(body, uint16(2*len(anonSuites)))
+	for _, s := range anonSuites {
+		body = binary.BigEndian.AppendUint16(body, s.id)
+	}
+	body = append(body, 1, 0) // null compression only
+
+	// an empty renegotiation_info extension, RFC 5746
+	body = append(body, 0, 5, 0xff, 0x01, 0, 1, 0)
+
+	return handshakeMsg(typeClientHello, body)
+}
+
+func handshakeMsg(typ byte, body []byte) []byte {
+	return append([]byte{typ, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+func lengthPrefixed(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(b))), b...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// prf is the pseudorandom function of TLS 1.2, RFC 5246, section 5.
+func prf(h func() hash.Hash, secret []byte, label string, seed []byte, n int) []byte {
+	seed = concat([]byte(label), seed)
+	mac := hmac.New(h, secret)
+
+	mac.Write(seed)
+	a := mac.Sum(nil)
+
+	out := make([]byte, 0, n+mac.Size())
+	for len(out) < n {
+		mac.Reset()
+		mac.Write(a)
+		mac.Write(seed)
+		out = mac.Sum(out)
+
+		mac.Reset()
+		mac.Write(a)
+		a = mac.Sum(nil)
+	}
+	return out[:n]
+}
+
+// alertError is a fatal alert sent by the device.
+type alertError byte
+
+func (e alertError) Error() string {
+	switch e {
+	case 40:
+		return "remote error: tls: handshake failure"
+	case 70:
+		return "remote error: tls: protocol version not supported"
+	case 71:
+		return "remote error: tls: insufficient security level"
+	default:
+		return fmt.Sprintf("remote error: tls: alert(%d)", byte(e))
+	}
+}
+
+// alertErr returns io.EOF for close_notify, the error of a fatal alert, and nil for warnings.
+func alertErr(data []byte) error {
+	switch {
+	case len(data) != 2:
+		return fmt.Errorf("%w: invalid alert", ErrAnonymousDHHandshake)
+	case data[1] == 0:
+		return io.EOF
+	case data[0] == 1:
+		return nil
+	default:
+		return alertError(data[1])
+	}
+}
+
+// isHandshakeFailure reports whether the device refused a crypto/tls handshake with a
+// handshake_failure alert.
+func isHandshakeFailure(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error" && opErr.Err.Error() == "tls: handshake failure"
+}
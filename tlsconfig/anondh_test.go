@@ -0,0 +1,157 @@
+package tlsconfig
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// opensslServer starts an api-ssl service without certificate with openssl s_server, offering
+// cipher. It sends every line back reversed.
+func opensslServer(t *testing.T, cipher string) string {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl not found")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	cmd := exec.Command("openssl", "s_server", "-nocert", "-tls1_2", "-rev",
+		"-cipher", cipher+":@SECLEVEL=0", "-accept", address)
+	out, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	sc := bufio.NewScanner(out)
+	for sc.Scan() && sc.Text() != "ACCEPT" {
+	}
+	go func() {
+		for sc.Scan() {
+		}
+	}()
+	return address
+}
+
+func TestDialAnonymousDH(t *testing.T) {
+	for _, suite := range []struct {
+		name string
+		id   uint16
+	}{
+		{"ADH-AES256-GCM-SHA384", 0x00a7},
+		{"ADH-AES128-GCM-SHA256", 0x00a6},
+		{"ADH-AES256-SHA256", 0x006d},
+		{"ADH-AES128-SHA256", 0x006c},
+		{"ADH-AES256-SHA", 0x003a},
+		{"ADH-AES128-SHA", 0x0034},
+	} {
+		t.Run(suite.name, func(t *testing.T) {
+			address := opensslServer(t, suite.name)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			conn, err := DialAnonymousDH(ctx, "tcp", address)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			require.Equal(t, suite.id, conn.(interface{ ConnectionState() tls.ConnectionState }).ConnectionState().CipherSuite)
+
+			// longer than a record, in lines short enough for s_server
+			long := strings.Repeat("0123456789", 1000)
+			lines := []string{"hello", long, long}
+			_, err = conn.Write([]byte(strings.Join(lines, "\n") + "\n"))
+			require.NoError(t, err)
+
+			r := bufio.NewReader(conn)
+			for _, line := range lines {
+				got, err := r.ReadString('\n')
+				require.NoError(t, err)
+				require.Equal(t, reverse(line)+"\n", got)
+			}
+		})
+	}
+}
+
+func TestAnonymousDHFallback(t *testing.T) {
+	address := opensslServer(t, "ADH")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cfg, err := Pinned(Fingerprint(newCert(t).Leaf))
+	require.NoError(t, err)
+
+	conn, err := AnonymousDHFallback(cfg)(ctx, "tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("abc\n"))
+	require.NoError(t, err)
+	got, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "cba\n", got)
+}
+
+func TestAnonymousDHCanceled(t *testing.T) {
+	a, b := tcpPair(t)
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		// read the ClientHello and never answer
+		_, _ = b.Read(make([]byte, 1024))
+		cancel()
+	}()
+
+	_, err := AnonymousDHClient(ctx, a)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHalfConn(t *testing.T) {
+	master := make([]byte, 48)
+	random := make([]byte, 32)
+
+	for i := range anonSuites {
+		s := &anonSuites[i]
+		client, server, err := s.keys(master, random, random)
+		require.NoError(t, err)
+
+		// what the client seals, only the same keys open
+		record := client.seal(recordApplicationData, tls.VersionTLS12, []byte("hello"))
+		peer, _, err := s.keys(master, random, random)
+		require.NoError(t, err)
+		data, err := peer.open(recordApplicationData, tls.VersionTLS12, record[5:])
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+
+		_, err = server.open(recordApplicationData, tls.VersionTLS12, record[5:])
+		require.ErrorIs(t, err, ErrAnonymousDHHandshake)
+
+		// and tampered records are refused
+		record = client.seal(recordApplicationData, tls.VersionTLS12, []byte("hello"))
+		record[len(record)-1] ^= 1
+		_, err = peer.open(recordApplicationData, tls.VersionTLS12, record[5:])
+		require.ErrorIs(t, err, ErrAnonymousDHHandshake)
+	}
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
@@ -0,0 +1,110 @@
+package tlsconfig
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MismatchError is returned when a device trusted on first use shows another certificate.
+type MismatchError struct {
+	Host string
+	// Want is the fingerprint recorded for Host, Got the one of the certificate shown.
+	Want, Got string
+}
+
+func (err *MismatchError) Error() string {
+	return fmt.Sprintf("certificate of %s changed: got %s, want %s", err.Host, err.Got, err.Want)
+}
+
+// Store records the certificate fingerprints of the devices trusted on first use.
+type Store interface {
+	// Lookup returns the fingerprint recorded for host, or false if there is none.
+	Lookup(host string) (string, bool, error)
+	Save(host, fingerprint string) error
+}
+
+// TOFU returns a configuration trusting the certificate of host the first time it is seen,
+// and recording it in s. Later connections fail with a *MismatchError if the certificate differs.
+func TOFU(s Store, host string) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // verified by VerifyConnection
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			got := Fingerprint(cs.PeerCertificates[0])
+
+			want, ok, err := s.Lookup(host)
+			switch {
+			case err != nil:
+				return err
+			case !ok:
+				return s.Save(host, got)
+			case want != got:
+				return &MismatchError{Host: host, Want: want, Got: got}
+			}
+			return nil
+		},
+	}
+}
+
+// KnownHosts is a Store kept in a file in the style of the known_hosts of SSH: one line per
+// device with its host and fingerprint, separated by a space. Lines starting with # are comments.
+type KnownHosts struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewKnownHosts returns a KnownHosts kept in the file at path, created when the first device
+// is saved.
+func NewKnownHosts(path string) *KnownHosts {
+	return &KnownHosts{path: path}
+}
+
+// Lookup implements Store.
+func (k *KnownHosts) Lookup(host string) (string, bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	f, err := os.Open(k.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("known hosts: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if h, fp, ok := strings.Cut(line, " "); ok && h == host {
+			return strings.TrimSpace(fp), true, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", false, fmt.Errorf("known hosts %s: %w", k.path, err)
+	}
+	return "", false, nil
+}
+
+// Save implements Store. It appends a line to the file.
+func (k *KnownHosts) Save(host, fingerprint string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	f, err := os.OpenFile(k.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("known hosts: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", host, fingerprint); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("known hosts %s: %w", k.path, err)
+	}
+	return f.Close()
+}
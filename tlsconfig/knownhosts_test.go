@@ -0,0 +1,54 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOFU(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	hosts := NewKnownHosts(path)
+
+	cert := newCert(t)
+	server := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	// trusted and recorded on first use
+	require.NoError(t, handshake(t, TOFU(hosts, "router:8729"), server))
+	fp, ok, err := hosts.Lookup("router:8729")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Fingerprint(cert.Leaf), fp)
+
+	require.NoError(t, handshake(t, TOFU(hosts, "router:8729"), server))
+
+	other := &tls.Config{Certificates: []tls.Certificate{newCert(t)}}
+	err = handshake(t, TOFU(hosts, "router:8729"), other)
+	var mismatch *MismatchError
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, fp, mismatch.Want)
+
+	// other hosts are independent
+	require.NoError(t, handshake(t, TOFU(hosts, "switch:8729"), other))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "router:8729 "+fp+"\nswitch:8729 "+Fingerprint(other.Certificates[0].Leaf)+"\n", string(b))
+}
+
+func TestKnownHostsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(path, []byte("# routers\n\nrouter abc\n"), 0o600))
+
+	fp, ok, err := NewKnownHosts(path).Lookup("router")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "abc", fp)
+
+	_, ok, err = NewKnownHosts(path).Lookup("#")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,130 @@
+/*
+Package tlsconfig builds TLS configurations for the api-ssl service of RouterOS devices, which
+usually have self-signed certificates. Instead of disabling verification, pin the certificate
+of the device with Pinned, or trust it on first use with TOFU:
+
+	cfg, err := tlsconfig.Pinned("3f:4a:...:9e")
+	if err != nil {
+		return err
+	}
+	c, err := routeros.DialTLS("192.168.88.1:8729", "admin", "", cfg)
+
+Devices whose api-ssl service has no certificate only offer anonymous Diffie-Hellman cipher
+suites, which crypto/tls does not implement. The device refuses the handshakes of crypto/tls
+with a bare handshake_failure alert, which CheckAnonymousDH adds a hint to. Better give the
+service a certificate and pin it, but where that cannot be done, connect with DialAnonymousDH,
+or fall back to it with AnonymousDHFallback:
+
+	c, err := (&routeros.Dialer{
+		Address:     "192.168.88.1:8729",
+		Credentials: routeros.StaticCredentials("admin", ""),
+		DialConn:    tlsconfig.AnonymousDHFallback(cfg),
+	}).DialContext(ctx)
+
+Anonymous Diffie-Hellman encrypts the connection but does not authenticate the device, so it
+only protects from eavesdropping.
+*/
+package tlsconfig
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotPinned is returned when the certificate of the device matches no pin.
+	ErrNotPinned = errors.New("certificate matches no pin")
+	// ErrAnonymousDH is the hint added by CheckAnonymousDH.
+	ErrAnonymousDH = errors.New("the api-ssl service may have no certificate, so that the device only offers anonymous cipher suites")
+)
+
+// spkiPrefix starts SPKI pins, as in curl --pinnedpubkey.
+const spkiPrefix = "sha256//"
+
+// Fingerprint returns the SHA-256 fingerprint of cert in hex, as RouterOS shows it in
+// /certificate print.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// SPKIFingerprint returns the pin of the public key of cert: "sha256//" followed by the SHA-256
+// of its SubjectPublicKeyInfo in base64. Unlike Fingerprint, it stays the same when the
+// certificate is renewed with the same key.
+func SPKIFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return spkiPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Pinned returns a configuration accepting only devices whose certificate matches one of
+// pins. A pin is either a Fingerprint, in any case and optionally with colons, or an
+// SPKIFingerprint. The certificate chain is not verified otherwise, as the pin replaces it.
+func Pinned(pins ...string) (*tls.Config, error) {
+	if len(pins) == 0 {
+		return nil, errors.New("no pin")
+	}
+
+	want := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		p, err := normalizePin(pin)
+		if err != nil {
+			return nil, err
+		}
+		want[p] = true
+	}
+
+	return &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // verified by VerifyConnection
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			cert := cs.PeerCertificates[0]
+			if want[Fingerprint(cert)] || want[SPKIFingerprint(cert)] {
+				return nil
+			}
+			return fmt.Errorf("%w: %s", ErrNotPinned, Fingerprint(cert))
+		},
+	}, nil
+}
+
+func normalizePin(pin string) (string, error) {
+	if strings.HasPrefix(pin, spkiPrefix) {
+		b, err := base64.StdEncoding.DecodeString(pin[len(spkiPrefix):])
+		if err != nil || len(b) != sha256.Size {
+			return "", fmt.Errorf("invalid SPKI pin %q", pin)
+		}
+		return pin, nil
+	}
+
+	p := strings.ToLower(strings.ReplaceAll(pin, ":", ""))
+	if b, err := hex.DecodeString(p); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid fingerprint pin %q", pin)
+	}
+	return p, nil
+}
+
+// LoadClientCertificate adds the certificate and key of the PEM files certFile and keyFile to
+// cfg, for devices requiring client certificates.
+func LoadClientCertificate(cfg *tls.Config, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("client certificate: %w", err)
+	}
+	cfg.Certificates = append(cfg.Certificates, cert)
+	return nil
+}
+
+// CheckAnonymousDH adds ErrAnonymousDH as a hint to err if the device refused the handshake with
+// a handshake_failure alert, as an api-ssl service without certificate does, and returns err as
+// is otherwise. The alert has other causes too, such as a client certificate being required, so
+// err comes first in the message.
+func CheckAnonymousDH(err error) error {
+	if isHandshakeFailure(err) {
+		return fmt.Errorf("%w (hint: %w)", err, ErrAnonymousDH)
+	}
+	return err
+}
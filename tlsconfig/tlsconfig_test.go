@@ -0,0 +1,141 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newCert returns a self-signed certificate, as RouterOS devices have.
+func newCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "router"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// handshake connects a client with cfg to a server with the server config, and returns the
+// error of the client. The connection is over TCP, whose buffers let either side fail the
+// handshake while the other is still writing.
+func handshake(t *testing.T, cfg *tls.Config, server *tls.Config) error {
+	a, b := tcpPair(t)
+
+	go func() {
+		_ = tls.Server(b, server).Handshake()
+		_ = b.Close()
+	}()
+
+	return tls.Client(a, cfg).Handshake()
+}
+
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	a, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	b, err := ln.Accept()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = a.Close()
+		_ = b.Close()
+	})
+	return a, b
+}
+
+func TestPinned(t *testing.T) {
+	cert := newCert(t)
+	server := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	fp := Fingerprint(cert.Leaf)
+	colons := strings.ToUpper(fp[:2] + ":" + fp[2:])
+
+	for _, pin := range []string{fp, colons, SPKIFingerprint(cert.Leaf)} {
+		cfg, err := Pinned(pin)
+		require.NoError(t, err)
+		require.NoError(t, handshake(t, cfg, server), pin)
+	}
+
+	other := Fingerprint(newCert(t).Leaf)
+	cfg, err := Pinned(other)
+	require.NoError(t, err)
+	require.ErrorIs(t, handshake(t, cfg, server), ErrNotPinned)
+
+	_, err = Pinned("abc")
+	require.Error(t, err)
+	_, err = Pinned("sha256//abc")
+	require.Error(t, err)
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	cert := newCert(t)
+	dir := t.TempDir()
+
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600))
+
+	serverCert := newCert(t)
+	cfg, err := Pinned(Fingerprint(serverCert.Leaf))
+	require.NoError(t, err)
+	require.NoError(t, LoadClientCertificate(cfg, certFile, keyFile))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	server := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	require.NoError(t, handshake(t, cfg, server))
+}
+
+func TestCheckAnonymousDH(t *testing.T) {
+	// a server without certificate cannot agree on a cipher suite, as api-ssl without one
+	cfg, err := Pinned(Fingerprint(newCert(t).Leaf))
+	require.NoError(t, err)
+	cfg.MaxVersion = tls.VersionTLS12
+
+	a, b := tcpPair(t)
+	go func() {
+		defer b.Close()
+		// answer the client hello with a handshake_failure alert, as RouterOS does
+		buf := make([]byte, 4096)
+		_, _ = b.Read(buf)
+		_, _ = b.Write([]byte{21, 3, 3, 0, 2, 2, 40})
+	}()
+
+	err = CheckAnonymousDH(tls.Client(a, cfg).Handshake())
+	require.ErrorIs(t, err, ErrAnonymousDH)
+	require.True(t, strings.HasPrefix(err.Error(), "remote error: tls: handshake failure (hint: "), err.Error())
+
+	require.Equal(t, os.ErrNotExist, CheckAnonymousDH(os.ErrNotExist))
+}
@@ -0,0 +1,171 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+	"github.com/go-routeros/routeros/v3/config"
+	"github.com/go-routeros/routeros/v3/proto"
+)
+
+// command is a sentence split into its parts.
+type command struct {
+	path string
+	verb string
+	// targets are the items named by =.id= or =numbers=, empty for menus without items.
+	targets []string
+	args    []proto.Pair
+}
+
+func parseCommand(sentence []string) (*command, error) {
+	if len(sentence) == 0 {
+		return nil, fmt.Errorf("%w: empty sentence", ErrNoInverse)
+	}
+
+	i := strings.LastIndexByte(sentence[0], '/')
+	if i <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInverse, sentence[0])
+	}
+	cmd := &command{path: sentence[0][:i], verb: sentence[0][i+1:]}
+
+	for _, word := range sentence[1:] {
+		if !strings.HasPrefix(word, "=") {
+			return nil, fmt.Errorf("%w: %s with %s", ErrNoInverse, sentence[0], word)
+		}
+		k, v, _ := strings.Cut(word[1:], "=")
+		if k == ".id" || k == "numbers" {
+			cmd.targets = append(cmd.targets, strings.Split(v, ",")...)
+			continue
+		}
+		cmd.args = append(cmd.args, proto.Pair{Key: k, Value: v})
+	}
+	return cmd, nil
+}
+
+// inverse returns the commands undoing cmd, reading the current state of the items it changes.
+// The inverse of add is only known once it ran, so it is left to the caller.
+func (cmd *command) inverse(ctx context.Context, c *routeros.Client) ([][]string, error) {
+	switch cmd.verb {
+	case "add":
+		return nil, nil
+	case "set", "unset", "enable", "disable", "remove":
+	default:
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoInverse, cmd.path, cmd.verb)
+	}
+
+	items, err := cmd.items(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var inverse [][]string
+	for _, item := range items {
+		if cmd.verb == "remove" {
+			inverse = append(inverse, readd(cmd.path, item))
+			continue
+		}
+
+		set, unset := cmd.restore(item)
+		if len(set) > 0 {
+			inverse = append(inverse, append(append([]string{cmd.path + "/set"}, selector(item)...), set...))
+		}
+		for _, k := range unset {
+			inverse = append(inverse, append(append([]string{cmd.path + "/unset"}, selector(item)...), "=value-name="+k))
+		}
+	}
+	return inverse, nil
+}
+
+// items reads the items cmd changes, as they are before it runs.
+func (cmd *command) items(ctx context.Context, c *routeros.Client) ([]map[string]string, error) {
+	if len(cmd.targets) == 0 {
+		r, err := c.RunContext(ctx, cmd.path+"/print")
+		if err != nil {
+			return nil, err
+		}
+		if len(r.Re) != 1 || r.Re[0].ID() != "" {
+			return nil, fmt.Errorf("%w: %s/%s without item", ErrNoInverse, cmd.path, cmd.verb)
+		}
+		return []map[string]string{r.Re[0].Map}, nil
+	}
+
+	items := make([]map[string]string, 0, len(cmd.targets))
+	for _, target := range cmd.targets {
+		query := "?name=" + target
+		if strings.HasPrefix(target, "*") {
+			query = "?.id=" + target
+		}
+
+		r, err := c.RunContext(ctx, cmd.path+"/print", query)
+		if err != nil {
+			return nil, err
+		}
+		if len(r.Re) == 0 {
+			return nil, fmt.Errorf("%w: %s: no such item %s", ErrNoInverse, cmd.path, target)
+		}
+		items = append(items, r.Re[0].Map)
+	}
+	return items, nil
+}
+
+// restore returns the attributes to set, and those to unset, to bring item back to its state.
+func (cmd *command) restore(item map[string]string) (set []string, unset []string) {
+	var keys []string
+	switch cmd.verb {
+	case "enable", "disable":
+		keys = []string{"disabled"}
+	case "unset":
+		for _, a := range cmd.args {
+			if a.Key == "value-name" {
+				keys = append(keys, strings.Split(a.Value, ",")...)
+			}
+		}
+	default:
+		for _, a := range cmd.args {
+			keys = append(keys, a.Key)
+		}
+	}
+
+	for _, k := range keys {
+		if v, ok := item[k]; ok {
+			set = append(set, "="+k+"="+v)
+		} else {
+			unset = append(unset, k)
+		}
+	}
+	return set, unset
+}
+
+func selector(item map[string]string) []string {
+	if id := item[proto.AttrID]; id != "" {
+		return []string{"=.id=" + id}
+	}
+	return nil
+}
+
+// readd returns the add command creating item again, without its read-only attributes.
+func readd(path string, item map[string]string) []string {
+	skip := make(map[string]bool)
+	for _, list := range [][]string{config.DefaultIgnore, config.DefaultReadOnly} {
+		for _, k := range list {
+			skip[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(item))
+	for k, v := range item {
+		if !skip[k] && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	cmd := []string{path + "/add"}
+	for _, k := range keys {
+		cmd = append(cmd, "="+k+"="+item[k])
+	}
+	return cmd
+}
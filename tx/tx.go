@@ -0,0 +1,209 @@
+/*
+Package tx applies changes to RouterOS devices as transactions: every command run through a
+Tx is recorded along with its inverse, so that the changes can be undone in reverse order
+when a later command fails or when Rollback is called.
+
+Inverses are found for the common commands of configuration menus:
+
+  - add is undone by removing the item with the id returned by the device;
+  - set, unset, enable and disable are undone by setting the previous values, read before
+    the command runs;
+  - remove is undone by adding the removed items again. They get new ids and, in ordered
+    menus such as firewall rules, go to the end of the list.
+
+Other commands need an explicit inverse, given to RunWithInverse.
+*/
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-routeros/routeros/v3"
+)
+
+var (
+	// ErrNoInverse is returned for commands whose inverse is not known.
+	ErrNoInverse = errors.New("no inverse for command")
+	// ErrDone is returned when a Tx is used after Commit or Rollback.
+	ErrDone = errors.New("transaction already committed or rolled back")
+)
+
+// Step is a command run in a transaction, with the commands undoing it.
+type Step struct {
+	Command []string
+	Inverse [][]string
+}
+
+// Report tells what Rollback undid.
+type Report struct {
+	// Undone are the steps whose inverses ran successfully, in the order they were undone.
+	Undone []Step
+	// Failed are the steps that could not be undone.
+	Failed []Step
+}
+
+// AbortError is returned when a command of a transaction fails. The transaction is rolled
+// back before the error is returned.
+type AbortError struct {
+	Command []string
+	Err     error
+	// Rollback reports what was undone, and RollbackErr why some steps could not be.
+	Rollback    *Report
+	RollbackErr error
+}
+
+func (err *AbortError) Error() string {
+	msg := fmt.Sprintf("%s: %v (rolled back %d steps)", err.Command[0], err.Err, len(err.Rollback.Undone))
+	if err.RollbackErr != nil {
+		msg += fmt.Sprintf(", rollback failed: %v", err.RollbackErr)
+	}
+	return msg
+}
+
+func (err *AbortError) Unwrap() error {
+	return err.Err
+}
+
+// Tx is a transaction on a routeros.Client. It is not safe for concurrent use.
+type Tx struct {
+	c     *routeros.Client
+	steps []Step
+	done  bool
+}
+
+// Begin starts a transaction on c.
+func Begin(c *routeros.Client) *Tx {
+	return &Tx{c: c}
+}
+
+// Steps returns the steps applied so far.
+func (t *Tx) Steps() []Step {
+	return t.steps
+}
+
+// Run simply calls RunArgs().
+func (t *Tx) Run(ctx context.Context, sentence ...string) (*routeros.Reply, error) {
+	return t.RunArgs(ctx, sentence)
+}
+
+// RunArgs finds the inverse of sentence, runs it and records it. If the inverse cannot be
+// found, nothing is run and ErrNoInverse is returned. If the command fails, the transaction
+// is rolled back and an *AbortError is returned.
+func (t *Tx) RunArgs(ctx context.Context, sentence []string) (*routeros.Reply, error) {
+	if t.done {
+		return nil, ErrDone
+	}
+
+	cmd, err := parseCommand(sentence)
+	if err != nil {
+		return nil, err
+	}
+	inverse, err := cmd.inverse(ctx, t.c)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := t.c.RunArgsContext(ctx, sentence)
+	if err != nil {
+		return nil, t.abort(ctx, sentence, err)
+	}
+	// Done is nil if the client was closed while the command ran, so it may have been applied:
+	// the step is kept, without the id of an added item, and rolled back
+	if r.Done == nil {
+		t.steps = append(t.steps, Step{Command: sentence, Inverse: inverse})
+		return nil, t.abort(ctx, sentence, io.ErrUnexpectedEOF)
+	}
+
+	// without a returned id, the step is kept without inverse and reported as failed on rollback
+	if id := r.Done.Map["ret"]; cmd.verb == "add" && id != "" {
+		inverse = [][]string{{cmd.path + "/remove", "=.id=" + id}}
+	}
+
+	t.steps = append(t.steps, Step{Command: sentence, Inverse: inverse})
+	return r, nil
+}
+
+// RunWithInverse runs sentence and records inverse as the commands undoing it. A step without
+// inverse is reported as failed by Rollback.
+func (t *Tx) RunWithInverse(ctx context.Context, sentence []string, inverse ...[]string) (*routeros.Reply, error) {
+	if t.done {
+		return nil, ErrDone
+	}
+
+	r, err := t.c.RunArgsContext(ctx, sentence)
+	if err != nil {
+		return nil, t.abort(ctx, sentence, err)
+	}
+
+	t.steps = append(t.steps, Step{Command: sentence, Inverse: inverse})
+	return r, nil
+}
+
+func (t *Tx) abort(ctx context.Context, sentence []string, err error) error {
+	report, rerr := t.Rollback(ctx)
+	return &AbortError{Command: sentence, Err: err, Rollback: report, RollbackErr: rerr}
+}
+
+// Commit ends the transaction, keeping the changes.
+func (t *Tx) Commit() error {
+	if t.done {
+		return ErrDone
+	}
+	t.done = true
+	t.steps = nil
+	return nil
+}
+
+// Rollback ends the transaction, undoing the applied steps in reverse order. It carries on
+// when an inverse fails, and returns the errors joined.
+func (t *Tx) Rollback(ctx context.Context) (*Report, error) {
+	if t.done {
+		return &Report{}, ErrDone
+	}
+	t.done = true
+
+	report := &Report{}
+	var errs []error
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		step := t.steps[i]
+
+		failed := len(step.Inverse) == 0
+		if failed {
+			errs = append(errs, fmt.Errorf("undo %s: %w", step.Command[0], ErrNoInverse))
+		}
+		for _, inv := range step.Inverse {
+			if _, err := t.c.RunArgsContext(ctx, inv); err != nil {
+				errs = append(errs, fmt.Errorf("undo %s: %s: %w", step.Command[0], strings.Join(inv, " "), err))
+				failed = true
+			}
+		}
+
+		if failed {
+			report.Failed = append(report.Failed, step)
+		} else {
+			report.Undone = append(report.Undone, step)
+		}
+	}
+	t.steps = nil
+
+	return report, errors.Join(errs...)
+}
+
+// Apply runs sentences in a transaction and commits it if they all succeed.
+func Apply(ctx context.Context, c *routeros.Client, sentences [][]string) error {
+	t := Begin(c)
+	for _, sentence := range sentences {
+		if _, err := t.RunArgs(ctx, sentence); err != nil {
+			if !errors.As(err, new(*AbortError)) {
+				// the command did not run, but the previous ones did
+				return t.abort(ctx, sentence, err)
+			}
+			return err
+		}
+	}
+	return t.Commit()
+}
@@ -0,0 +1,158 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-routeros/routeros/v3/internal/routerostest"
+)
+
+func TestRollbackOnError(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		// add
+		s.ReadSentence(t, "/ip/address/add @ [{`address` `10.0.0.1/24`} {`interface` `ether2`}]")
+		s.WriteSentence(t, "!done", "=ret=*5")
+		// set, by name
+		s.ReadSentence(t, "/interface/print @ [] ?[`name=ether2`]")
+		s.WriteSentence(t, "!re", "=.id=*2", "=name=ether2", "=mtu=1500")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/interface/set @ [{`numbers` `ether2`} {`mtu` `9000`} {`comment` `lan`}]")
+		s.WriteSentence(t, "!done")
+		// remove
+		s.ReadSentence(t, "/ip/firewall/filter/print @ [] ?[`.id=*A`]")
+		s.WriteSentence(t, "!re", "=.id=*A", "=chain=input", "=action=drop", "=bytes=100", "=invalid=false", "=comment=")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/remove @ [{`.id` `*A`}]")
+		s.WriteSentence(t, "!done")
+		// singleton set
+		s.ReadSentence(t, "/system/identity/print @ []")
+		s.WriteSentence(t, "!re", "=name=MikroTik")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/system/identity/set @ [{`name` `new`}]")
+		s.WriteSentence(t, "!done")
+		// failing add
+		s.ReadSentence(t, "/ip/route/add @ [{`gateway` `bogus`}]")
+		s.WriteSentence(t, "!trap", "=message=invalid value for argument gateway")
+		s.WriteSentence(t, "!done")
+
+		// rollback, in reverse order
+		s.ReadSentence(t, "/system/identity/set @ [{`name` `MikroTik`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/firewall/filter/add @ [{`action` `drop`} {`chain` `input`}]")
+		s.WriteSentence(t, "!done", "=ret=*B")
+		s.ReadSentence(t, "/interface/set @ [{`.id` `*2`} {`mtu` `1500`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/interface/unset @ [{`.id` `*2`} {`value-name` `comment`}]")
+		s.WriteSentence(t, "!trap", "=message=failure: cannot unset")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/ip/address/remove @ [{`.id` `*5`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	err := Apply(context.Background(), c, [][]string{
+		{"/ip/address/add", "=address=10.0.0.1/24", "=interface=ether2"},
+		{"/interface/set", "=numbers=ether2", "=mtu=9000", "=comment=lan"},
+		{"/ip/firewall/filter/remove", "=.id=*A"},
+		{"/system/identity/set", "=name=new"},
+		{"/ip/route/add", "=gateway=bogus"},
+	})
+
+	var abort *AbortError
+	require.True(t, errors.As(err, &abort))
+	require.Equal(t, []string{"/ip/route/add", "=gateway=bogus"}, abort.Command)
+	require.ErrorContains(t, abort.Err, "invalid value for argument gateway")
+	require.ErrorContains(t, abort.RollbackErr, "cannot unset")
+
+	var undone []string
+	for _, step := range abort.Rollback.Undone {
+		undone = append(undone, step.Command[0])
+	}
+	require.Equal(t, []string{"/system/identity/set", "/ip/firewall/filter/remove", "/ip/address/add"}, undone)
+	require.Len(t, abort.Rollback.Failed, 1)
+	require.Equal(t, "/interface/set", abort.Rollback.Failed[0].Command[0])
+}
+
+func TestAbortOnClose(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+	c.Async()
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/ip/address/add @r1 [{`address` `10.0.0.1/24`} {`interface` `ether2`}]")
+		// let the write of the command return before closing
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, c.Close())
+	})
+
+	_, err := Begin(c).Run(context.Background(), "/ip/address/add", "=address=10.0.0.1/24", "=interface=ether2")
+
+	var abort *AbortError
+	require.True(t, errors.As(err, &abort))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorIs(t, abort.RollbackErr, ErrNoInverse)
+	require.Len(t, abort.Rollback.Failed, 1)
+}
+
+func TestCommit(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/interface/print @ [] ?[`.id=*1`]")
+		s.WriteSentence(t, "!re", "=.id=*1", "=name=ether1", "=disabled=false")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/interface/disable @ [{`.id` `*1`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	tx := Begin(c)
+	_, err := tx.Run(context.Background(), "/interface/disable", "=.id=*1")
+	require.NoError(t, err)
+	require.Equal(t, []Step{{
+		Command: []string{"/interface/disable", "=.id=*1"},
+		Inverse: [][]string{{"/interface/set", "=.id=*1", "=disabled=false"}},
+	}}, tx.Steps())
+
+	require.NoError(t, tx.Commit())
+	_, err = tx.Run(context.Background(), "/interface/enable", "=.id=*1")
+	require.ErrorIs(t, err, ErrDone)
+	_, err = tx.Rollback(context.Background())
+	require.ErrorIs(t, err, ErrDone)
+}
+
+func TestExplicitRollback(t *testing.T) {
+	c, s := routerostest.NewPair(t)
+
+	s.Serve(t, func() {
+		s.ReadSentence(t, "/system/reboot @ []")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/tool/netwatch/add @ [{`host` `192.0.2.1`}]")
+		s.WriteSentence(t, "!done")
+		s.ReadSentence(t, "/log/info @ [{`message` `undo`}]")
+		s.WriteSentence(t, "!done")
+	})
+
+	tx := Begin(c)
+	ctx := context.Background()
+
+	_, err := tx.Run(ctx, "/system/reboot")
+	require.ErrorIs(t, err, ErrNoInverse)
+
+	_, err = tx.RunWithInverse(ctx, []string{"/system/reboot"}, []string{"/log/info", "=message=undo"})
+	require.NoError(t, err)
+
+	// no id returned: kept without inverse
+	_, err = tx.Run(ctx, "/tool/netwatch/add", "=host=192.0.2.1")
+	require.NoError(t, err)
+
+	report, err := tx.Rollback(ctx)
+	require.ErrorIs(t, err, ErrNoInverse)
+	require.Len(t, report.Failed, 1)
+	require.Len(t, report.Undone, 1)
+	require.Equal(t, []string{"/system/reboot"}, report.Undone[0].Command)
+}
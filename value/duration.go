@@ -0,0 +1,198 @@
+package value
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var durationUnits = []struct {
+	name string
+	unit time.Duration
+}{
+	{"w", week},
+	{"d", day},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// ParseDuration parses a RouterOS duration. Both the unit form (1w2d3h4m5s, 150ms, 1.5s) and the
+// clock form (00:01:02.345, 1d02:03:04) are accepted, as well as mixes of them (1w2d03:04:05).
+// A plain number is a number of seconds.
+func ParseDuration(s string) (time.Duration, error) {
+	in := s
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, syntaxError("duration", in)
+	}
+
+	var clock string
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		// the hours of the clock part start after the last unit letter
+		start := strings.LastIndexFunc(s[:i], func(r rune) bool { return r < '0' || r > '9' }) + 1
+		s, clock = s[:start], s[start:]
+
+		// whatever precedes the clock must end with a unit, e.g. 1d in 1d02:03:04
+		if last := len(s) - 1; last >= 0 && (s[last] < 'a' || s[last] > 'z') {
+			return 0, syntaxError("duration", in)
+		}
+	}
+
+	d, err := parseUnits(s)
+	if err != nil {
+		return 0, syntaxError("duration", in)
+	}
+
+	if clock != "" {
+		c, err := parseClock(clock)
+		if err != nil {
+			return 0, syntaxError("duration", in)
+		}
+		d += c
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// parseUnits parses the unit form, e.g. 1w2d3h4m5s.
+func parseUnits(s string) (time.Duration, error) {
+	var d time.Duration
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i == 0 {
+			return 0, ErrSyntax
+		}
+		if i < 0 {
+			i = len(s)
+		}
+		num := s[:i]
+		s = s[i:]
+
+		// a trailing number without unit is in seconds
+		unit := time.Second
+		if s != "" {
+			j := strings.IndexFunc(s, func(r rune) bool { return r >= '0' && r <= '9' })
+			if j < 0 {
+				j = len(s)
+			}
+			var ok bool
+			if unit, ok = lookupDurationUnit(s[:j]); !ok {
+				return 0, ErrSyntax
+			}
+			s = s[j:]
+		}
+
+		v, err := scale(num, int64(unit))
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(v)
+	}
+	return d, nil
+}
+
+func lookupDurationUnit(name string) (time.Duration, bool) {
+	for _, u := range durationUnits {
+		if u.name == name {
+			return u.unit, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses hh:mm:ss[.fraction] or mm:ss[.fraction].
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, ErrSyntax
+	}
+
+	units := []time.Duration{time.Minute, time.Second}
+	if len(parts) == 3 {
+		units = []time.Duration{time.Hour, time.Minute, time.Second}
+	}
+
+	var d time.Duration
+	for i, part := range parts {
+		if part == "" || (i < len(parts)-1 && strings.Contains(part, ".")) {
+			return 0, ErrSyntax
+		}
+		v, err := scale(part, int64(units[i]))
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(v)
+	}
+	return d, nil
+}
+
+// scale returns the decimal number num multiplied by unit, truncated towards zero.
+func scale(num string, unit int64) (int64, error) {
+	whole, frac, _ := strings.Cut(num, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrSyntax
+	}
+
+	var v int64
+	if whole != "" {
+		w, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return 0, ErrSyntax
+		}
+		if w > math.MaxInt64/unit {
+			return 0, ErrSyntax
+		}
+		v = w * unit
+	}
+
+	// every extra digit of the fraction is worth ten times less
+	for place := unit; frac != ""; frac = frac[1:] {
+		c := frac[0]
+		if c < '0' || c > '9' {
+			return 0, ErrSyntax
+		}
+		place /= 10
+		v += int64(c-'0') * place
+	}
+	return v, nil
+}
+
+// FormatDuration formats d in the unit form RouterOS uses, e.g. 1w2d3h4m5s or 1s500ms.
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var sb strings.Builder
+	u := uint64(d)
+	if d < 0 {
+		sb.WriteByte('-')
+		u = -u
+	}
+
+	for _, unit := range durationUnits {
+		if n := u / uint64(unit.unit); n > 0 {
+			sb.WriteString(strconv.FormatUint(n, 10))
+			sb.WriteString(unit.name)
+			u -= n * uint64(unit.unit)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package value
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	for _, d := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0s", 0},
+		{"30", 30 * time.Second},
+		{"5s", 5 * time.Second},
+		{"150ms", 150 * time.Millisecond},
+		{"1.5s", 1500 * time.Millisecond},
+		{"1w2d3h4m5s", week + 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{"1s500ms", 1500 * time.Millisecond},
+		{"10us", 10 * time.Microsecond},
+		{"00:01:02.345", time.Minute + 2*time.Second + 345*time.Millisecond},
+		{"01:02", time.Minute + 2*time.Second},
+		{"1d02:03:04", day + 2*time.Hour + 3*time.Minute + 4*time.Second},
+		{"1w2d03:04:05", week + 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{"-5m", -5 * time.Minute},
+	} {
+		t.Run(d.in, func(t *testing.T) {
+			have, err := ParseDuration(d.in)
+			require.NoError(t, err)
+			require.Equal(t, d.want, have)
+		})
+	}
+}
+
+func TestParseDurationInvalid(t *testing.T) {
+	for _, in := range []string{"", "-", "s", "5x", "1:2:3:4", "1.5:00", "a1s", "1d:00", "9999999999999w"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := ParseDuration(in)
+			require.ErrorIs(t, err, ErrSyntax)
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, d := range []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{5 * time.Second, "5s"},
+		{1500 * time.Millisecond, "1s500ms"},
+		{week + 2*day + 3*time.Hour + 4*time.Minute + 5*time.Second, "1w2d3h4m5s"},
+		{-90 * time.Second, "-1m30s"},
+		{time.Hour + time.Nanosecond, "1h1ns"},
+	} {
+		t.Run(d.want, func(t *testing.T) {
+			require.Equal(t, d.want, FormatDuration(d.in))
+
+			back, err := ParseDuration(d.want)
+			require.NoError(t, err)
+			require.Equal(t, d.in, back, "round trip")
+		})
+	}
+}
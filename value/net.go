@@ -0,0 +1,56 @@
+package value
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ParseAddr parses an IPv4 or IPv6 address.
+func ParseAddr(s string) (netip.Addr, error) {
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, syntaxError("address", s)
+	}
+	return a, nil
+}
+
+// ParsePrefix parses a prefix such as 192.168.0.0/24. Host bits are kept, so interface addresses
+// like 192.168.88.1/24 are accepted too. An address without a prefix length is a single host prefix.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, syntaxError("prefix", s)
+		}
+		return netip.PrefixFrom(a, a.BitLen()), nil
+	}
+
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, syntaxError("prefix", s)
+	}
+	return p, nil
+}
+
+// FormatPrefix formats p, omitting the length of single host prefixes as RouterOS does.
+func FormatPrefix(p netip.Prefix) string {
+	if p.IsSingleIP() {
+		return p.Addr().String()
+	}
+	return p.String()
+}
+
+// ParseMAC parses a 48-bit MAC address such as 4C:5E:0C:12:34:56.
+func ParseMAC(s string) (net.HardwareAddr, error) {
+	mac, err := net.ParseMAC(s)
+	if err != nil || len(mac) != 6 {
+		return nil, syntaxError("MAC address", s)
+	}
+	return mac, nil
+}
+
+// FormatMAC formats mac in upper case with colons, as RouterOS does.
+func FormatMAC(mac net.HardwareAddr) string {
+	return strings.ToUpper(mac.String())
+}
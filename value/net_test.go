@@ -0,0 +1,58 @@
+package value
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefix(t *testing.T) {
+	for _, d := range []struct {
+		in     string
+		want   netip.Prefix
+		format string
+	}{
+		{"192.168.0.0/24", netip.MustParsePrefix("192.168.0.0/24"), "192.168.0.0/24"},
+		{"192.168.88.1/24", netip.MustParsePrefix("192.168.88.1/24"), "192.168.88.1/24"},
+		{"10.0.0.1", netip.MustParsePrefix("10.0.0.1/32"), "10.0.0.1"},
+		{"2001:db8::/32", netip.MustParsePrefix("2001:db8::/32"), "2001:db8::/32"},
+		{"2001:db8::1", netip.MustParsePrefix("2001:db8::1/128"), "2001:db8::1"},
+	} {
+		t.Run(d.in, func(t *testing.T) {
+			p, err := ParsePrefix(d.in)
+			require.NoError(t, err)
+			require.Equal(t, d.want, p)
+			require.Equal(t, d.format, FormatPrefix(p))
+		})
+	}
+
+	for _, in := range []string{"", "10.0.0.0/33", "host", "10.0.0/8"} {
+		_, err := ParsePrefix(in)
+		require.ErrorIs(t, err, ErrSyntax, in)
+	}
+}
+
+func TestAddr(t *testing.T) {
+	a, err := ParseAddr("192.168.88.1")
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddr("192.168.88.1"), a)
+
+	_, err = ParseAddr("192.168.88.1/24")
+	require.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestMAC(t *testing.T) {
+	mac, err := ParseMAC("4c:5e:0c:12:34:56")
+	require.NoError(t, err)
+	require.Equal(t, "4C:5E:0C:12:34:56", FormatMAC(mac))
+
+	back, err := ParseMAC(FormatMAC(mac))
+	require.NoError(t, err)
+	require.Equal(t, mac, back)
+
+	for _, in := range []string{"", "4C:5E:0C:12:34", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01"} {
+		_, err := ParseMAC(in)
+		require.ErrorIs(t, err, ErrSyntax, in)
+	}
+}
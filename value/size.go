@@ -0,0 +1,188 @@
+package value
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+type multiple struct {
+	name  string
+	value uint64
+}
+
+// binary multiples, largest first
+var binaryMultiples = []multiple{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+}
+
+// decimal multiples, largest first
+var decimalMultiples = []multiple{
+	{"T", 1e12},
+	{"G", 1e9},
+	{"M", 1e6},
+	{"k", 1e3},
+}
+
+// RatePair is a pair of rates as used by queues, e.g. max-limit=10M/20M (upload/download).
+type RatePair struct {
+	Upload, Download uint64
+}
+
+func (p RatePair) String() string {
+	return FormatRatePair(p)
+}
+
+// ParseSize parses a size in bytes. Binary suffixes (KiB, MiB, GiB, TiB) are powers of 1024,
+// decimal ones (k, M, G, T, optionally followed by B) are powers of 1000. Fractions are allowed,
+// e.g. 1.5KiB is 1536.
+func ParseSize(s string) (uint64, error) {
+	num, suffix := splitNumber(s)
+	suffix = strings.TrimSuffix(suffix, "B")
+
+	unit := uint64(1)
+	if suffix != "" {
+		var ok bool
+		if unit, ok = lookupMultiple(suffix+"B", binaryMultiples); !ok {
+			if unit, ok = lookupMultiple(suffix, decimalMultiples); !ok {
+				return 0, syntaxError("size", s)
+			}
+		}
+	}
+
+	v, err := scaleUnsigned(num, unit)
+	if err != nil {
+		return 0, syntaxError("size", s)
+	}
+	return v, nil
+}
+
+// FormatSize formats n bytes using the largest binary suffix that keeps it exact to three decimals,
+// e.g. 1536 is 1.5KiB.
+func FormatSize(n uint64) string {
+	return formatMultiple(n, binaryMultiples, "B")
+}
+
+// ParseRate parses a rate in bits per second, e.g. 10M, 1.5k or 100kbps. Suffixes are decimal.
+func ParseRate(s string) (uint64, error) {
+	num, suffix := splitNumber(s)
+	suffix = strings.TrimSuffix(suffix, "bps")
+
+	unit := uint64(1)
+	if suffix != "" {
+		var ok bool
+		if unit, ok = lookupMultiple(suffix, decimalMultiples); !ok {
+			return 0, syntaxError("rate", s)
+		}
+	}
+
+	v, err := scaleUnsigned(num, unit)
+	if err != nil {
+		return 0, syntaxError("rate", s)
+	}
+	return v, nil
+}
+
+// FormatRate formats n bits per second using the largest decimal suffix that keeps it exact
+// to three decimals, e.g. 1500000 is 1.5M.
+func FormatRate(n uint64) string {
+	return formatMultiple(n, decimalMultiples, "")
+}
+
+// ParseRatePair parses two rates separated by a slash, e.g. 10M/20M. A single rate is used for both.
+func ParseRatePair(s string) (RatePair, error) {
+	up, down, found := strings.Cut(s, "/")
+	if !found {
+		down = up
+	}
+
+	var p RatePair
+	var err error
+	if p.Upload, err = ParseRate(up); err != nil {
+		return RatePair{}, syntaxError("rate pair", s)
+	}
+	if p.Download, err = ParseRate(down); err != nil {
+		return RatePair{}, syntaxError("rate pair", s)
+	}
+	return p, nil
+}
+
+// FormatRatePair formats p as upload/download, e.g. 10M/20M.
+func FormatRatePair(p RatePair) string {
+	return FormatRate(p.Upload) + "/" + FormatRate(p.Download)
+}
+
+// splitNumber splits s into the leading decimal number and the rest.
+func splitNumber(s string) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func lookupMultiple(name string, multiples []multiple) (uint64, bool) {
+	for _, m := range multiples {
+		if m.name == name || (m.name == "k" && name == "K") {
+			return m.value, true
+		}
+	}
+	return 0, false
+}
+
+// scaleUnsigned returns the decimal number num multiplied by unit, truncated towards zero.
+func scaleUnsigned(num string, unit uint64) (uint64, error) {
+	whole, frac, _ := strings.Cut(num, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrSyntax
+	}
+
+	var v uint64
+	if whole != "" {
+		w, err := strconv.ParseUint(whole, 10, 64)
+		if err != nil {
+			return 0, ErrSyntax
+		}
+		if unit > 1 && w > ^uint64(0)/unit {
+			return 0, ErrSyntax
+		}
+		v = w * unit
+	}
+
+	// fraction digits are accumulated exactly, then scaled once; digits beyond 1e-18 are ignored
+	var f, div uint64 = 0, 1
+	for _, c := range frac {
+		if c < '0' || c > '9' {
+			return 0, ErrSyntax
+		}
+		if div < 1e18 {
+			f = f*10 + uint64(c-'0')
+			div *= 10
+		}
+	}
+	if div > 1 {
+		// f < div, so the high word of f*unit is always below div
+		hi, lo := bits.Mul64(f, unit)
+		q, _ := bits.Div64(hi, lo, div)
+		v += q
+	}
+	return v, nil
+}
+
+func formatMultiple(n uint64, multiples []multiple, plain string) string {
+	for _, m := range multiples {
+		if n < m.value || (n%m.value)*1000%m.value != 0 {
+			continue
+		}
+		s := strconv.FormatUint(n/m.value, 10)
+		if rest := n % m.value * 1000 / m.value; rest != 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%03d", rest), "0")
+		}
+		return s + m.name
+	}
+	return strconv.FormatUint(n, 10) + plain
+}
@@ -0,0 +1,102 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for _, d := range []struct {
+		in   string
+		want uint64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"512B", 512},
+		{"1KiB", 1024},
+		{"1.5KiB", 1536},
+		{"2MiB", 2 << 20},
+		{"1.25GiB", 5 << 28},
+		{"1TiB", 1 << 40},
+		{"10k", 10000},
+		{"10K", 10000},
+		{"1.5MB", 1500000},
+		{"0.1KiB", 102},
+	} {
+		t.Run(d.in, func(t *testing.T) {
+			have, err := ParseSize(d.in)
+			require.NoError(t, err)
+			require.Equal(t, d.want, have)
+		})
+	}
+
+	for _, in := range []string{"", "KiB", "1XiB", "1.2.3", "99999999999TiB"} {
+		_, err := ParseSize(in)
+		require.ErrorIs(t, err, ErrSyntax, in)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, d := range []struct {
+		in   uint64
+		want string
+	}{
+		{0, "0B"},
+		{1000, "1000B"},
+		{1024, "1KiB"},
+		{1536, "1.5KiB"},
+		{1025, "1025B"},
+		{5 << 28, "1.25GiB"},
+		{3 << 40, "3TiB"},
+	} {
+		t.Run(d.want, func(t *testing.T) {
+			require.Equal(t, d.want, FormatSize(d.in))
+
+			back, err := ParseSize(d.want)
+			require.NoError(t, err)
+			require.Equal(t, d.in, back, "round trip")
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	for _, d := range []struct {
+		in     string
+		want   uint64
+		format string
+	}{
+		{"0", 0, "0"},
+		{"64000", 64000, "64k"},
+		{"10M", 10000000, "10M"},
+		{"1.5k", 1500, "1.5k"},
+		{"100kbps", 100000, "100k"},
+		{"2.5G", 2500000000, "2.5G"},
+		{"1234567", 1234567, "1234.567k"},
+		{"1234567.8", 1234567, "1234.567k"},
+	} {
+		t.Run(d.in, func(t *testing.T) {
+			have, err := ParseRate(d.in)
+			require.NoError(t, err)
+			require.Equal(t, d.want, have)
+			require.Equal(t, d.format, FormatRate(have))
+		})
+	}
+
+	_, err := ParseRate("10KiB")
+	require.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestRatePair(t *testing.T) {
+	p, err := ParseRatePair("10M/20M")
+	require.NoError(t, err)
+	require.Equal(t, RatePair{Upload: 10e6, Download: 20e6}, p)
+	require.Equal(t, "10M/20M", p.String())
+
+	p, err = ParseRatePair("512k")
+	require.NoError(t, err)
+	require.Equal(t, RatePair{Upload: 512e3, Download: 512e3}, p)
+
+	_, err = ParseRatePair("10M/x")
+	require.ErrorIs(t, err, ErrSyntax)
+}
@@ -0,0 +1,53 @@
+/*
+Package value parses and formats the textual values used by RouterOS, such as durations
+(1w2d3h4m5s), sizes (1.5KiB), rates (10M/20M), booleans (yes/no) and lists (a,b,c).
+
+The functions can be used on their own, on attribute values of proto.Sentence, or by codecs
+mapping sentences to structs.
+*/
+package value
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSyntax is wrapped by all the errors returned when a value cannot be parsed.
+var ErrSyntax = errors.New("invalid syntax")
+
+func syntaxError(kind, s string) error {
+	return fmt.Errorf("value: parse %s %q: %w", kind, s, ErrSyntax)
+}
+
+// ParseBool parses yes/no and true/false.
+func ParseBool(s string) (bool, error) {
+	switch s {
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	}
+	return false, syntaxError("bool", s)
+}
+
+// FormatBool returns "yes" or "no", which all RouterOS versions accept.
+func FormatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// ParseList splits a comma separated list. An empty string is an empty list.
+func ParseList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// FormatList joins list with commas.
+func FormatList(list []string) string {
+	return strings.Join(list, ",")
+}
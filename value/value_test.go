@@ -0,0 +1,30 @@
+package value
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBool(t *testing.T) {
+	for s, want := range map[string]bool{"yes": true, "true": true, "no": false, "false": false} {
+		b, err := ParseBool(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, b, s)
+
+		b, err = ParseBool(FormatBool(b))
+		require.NoError(t, err, s)
+		require.Equal(t, want, b, s)
+	}
+
+	_, err := ParseBool("maybe")
+	require.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestList(t *testing.T) {
+	require.Nil(t, ParseList(""))
+	require.Equal(t, []string{"a"}, ParseList("a"))
+	require.Equal(t, []string{"a", "b", "c"}, ParseList("a,b,c"))
+	require.Equal(t, "a,b,c", FormatList(ParseList("a,b,c")))
+	require.Equal(t, "", FormatList(nil))
+}
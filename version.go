@@ -0,0 +1,128 @@
+package routeros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a RouterOS version, e.g. 7.14.2 or 7.15beta4.
+type Version struct {
+	Major, Minor, Patch int
+	// Pre is the pre-release, e.g. "beta4" or "rc1", and empty for releases.
+	Pre string
+	// Channel is the release channel shown by the device, e.g. "stable" or "long-term".
+	// It is not compared.
+	Channel string
+}
+
+var versionRe = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?([a-z]+\d*)?(?:\s+\(([^)]*)\))?$`)
+
+// ParseVersion parses a version as shown by /system/resource, e.g. "7.14.2 (stable)".
+func ParseVersion(s string) (Version, error) {
+	m := versionRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid RouterOS version %q", s)
+	}
+
+	v := Version{Pre: m[4], Channel: m[5]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// String returns the version without its channel, e.g. "7.14.2".
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor)
+	if v.Patch != 0 {
+		s += "." + strconv.Itoa(v.Patch)
+	}
+	return s + v.Pre
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer than o. Pre-releases
+// come before their release, e.g. 7.15beta4 < 7.15rc1 < 7.15.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+
+	vName, vNum := splitPre(v.Pre)
+	oName, oNum := splitPre(o.Pre)
+	if c := strings.Compare(vName, oName); c != 0 {
+		return c
+	}
+	return sign(vNum - oNum)
+}
+
+// AtLeast reports whether v is major.minor.patch or newer.
+func (v Version) AtLeast(major, minor, patch int) bool {
+	return v.Compare(Version{Major: major, Minor: minor, Patch: patch}) >= 0
+}
+
+// splitPre splits a pre-release such as "beta4" into its name and number.
+func splitPre(pre string) (string, int) {
+	i := strings.IndexFunc(pre, func(r rune) bool { return r >= '0' && r <= '9' })
+	if i < 0 {
+		return pre, 0
+	}
+	n, _ := strconv.Atoi(pre[i:])
+	return pre[:i], n
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// Version returns the RouterOS version of the device, read from /system/resource the first
+// time and cached afterwards.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	c.capMu.Lock()
+	v := c.version
+	c.capMu.Unlock()
+	if v != nil {
+		return *v, nil
+	}
+
+	r, err := c.RunContext(ctx, "/system/resource/print", "=.proplist=version")
+	if err != nil {
+		return Version{}, err
+	}
+	if len(r.Re) == 0 {
+		return Version{}, errors.New("no version in /system/resource")
+	}
+
+	parsed, err := ParseVersion(r.Re[0].Map["version"])
+	if err != nil {
+		return Version{}, err
+	}
+
+	c.capMu.Lock()
+	c.version = &parsed
+	c.capMu.Unlock()
+
+	return parsed, nil
+}
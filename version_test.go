@@ -0,0 +1,68 @@
+package routeros
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Version
+	}{
+		{"7.14.2 (stable)", Version{Major: 7, Minor: 14, Patch: 2, Channel: "stable"}},
+		{"6.49.10 (long-term)", Version{Major: 6, Minor: 49, Patch: 10, Channel: "long-term"}},
+		{"7.15beta4 (testing)", Version{Major: 7, Minor: 15, Pre: "beta4", Channel: "testing"}},
+		{"7.1rc3", Version{Major: 7, Minor: 1, Pre: "rc3"}},
+		{"7.10", Version{Major: 7, Minor: 10}},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			v, err := ParseVersion(tc.in)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, v)
+		})
+	}
+
+	_, err := ParseVersion("seven")
+	require.Error(t, err)
+}
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []string{"6.48.6", "6.49", "6.49.10", "7.1beta2", "7.1beta10", "7.1rc1", "7.1", "7.1.1", "7.10", "7.14.2"}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := ParseVersion(ordered[i])
+			require.NoError(t, err)
+			b, err := ParseVersion(ordered[j])
+			require.NoError(t, err)
+			require.Equal(t, sign(i-j), a.Compare(b), "%s vs %s", a, b)
+		}
+	}
+
+	v, err := ParseVersion("7.13.1 (stable)")
+	require.NoError(t, err)
+	require.Equal(t, "7.13.1", v.String())
+	require.True(t, v.AtLeast(7, 13, 0))
+	require.False(t, v.AtLeast(7, 14, 0))
+}
+
+func TestClientVersion(t *testing.T) {
+	c, s := newPair(t)
+	defer deferCloser(t, c)
+
+	go func() {
+		defer deferCloser(t, s)
+		s.readSentence(t, "/system/resource/print @ [{`.proplist` `version`}]")
+		s.writeSentence(t, "!re", "=version=7.14.2 (stable)")
+		s.writeSentence(t, "!done")
+	}()
+
+	for i := 0; i < 2; i++ {
+		// the second call is answered from the cache
+		v, err := c.Version(context.Background())
+		require.NoError(t, err)
+		require.True(t, v.AtLeast(7, 14, 2))
+	}
+}